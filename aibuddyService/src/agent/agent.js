@@ -38,7 +38,7 @@ const graph = new StateGraph(MessagesAnnotation)
         return state
     })
     .addNode("chat", async (state, config) => {
-        const response = await model.invoke(state.messages, { tools: [ tools.searchProduct, tools.addProductToCart ] })
+        const response = await model.invoke(state.messages, { tools: [ tools.searchProduct, tools.addProductToCart, tools.recommendProducts ] })
         
 
         state.messages.push(new AIMessage({ content: response.text, tool_calls: response.tool_calls }))
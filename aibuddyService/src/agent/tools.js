@@ -49,4 +49,26 @@ const addProductToCart = tool(async ({ productId, quantity = 1, token }) => {
 })
 
 
-module.exports = { searchProduct, addProductToCart }
\ No newline at end of file
+const recommendProducts = tool(async ({ productId, kind = "related", token }) => {
+
+    const path = kind === "boughtTogether" ? "bought-together" : "related"
+
+    const response = await axios.get(`${process.env.PRODUCT_SERVICE_URL}/api/product/${encodeURIComponent(productId)}/${path}`, {
+        headers: {
+            Authorization: `Bearer ${token}`
+        }
+    })
+
+    return JSON.stringify(response.data)
+
+}, {
+    name: "recommendProducts",
+    description: "Recommend products for a given product: similar products (related) or products other customers bought with it (boughtTogether)",
+    schema: z.object({
+        productId: z.string().describe("The id of the product to base recommendations on"),
+        kind: z.enum([ "related", "boughtTogether" ]).describe("related for similar products, boughtTogether for products frequently bought with it").default("related"),
+    })
+})
+
+
+module.exports = { searchProduct, addProductToCart, recommendProducts }
\ No newline at end of file
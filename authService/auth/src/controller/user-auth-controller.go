@@ -35,6 +35,9 @@ func Register(c *gin.Context) {
 
     // Set a new ObjectID for the user
     newUser.ID = primitive.NewObjectID()
+    for i := range newUser.Addresses {
+        newUser.Addresses[i].ID = primitive.NewObjectID()
+    }
 
     // Hash the password
     hashPassword, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
@@ -179,6 +182,14 @@ func GetCurrentUser(c *gin.Context){
 		return ;
 	  }
 	  
+	  // addresses saved before they had IDs get one now, so checkout can refer to them
+	  if assignAddressIDs(user.Addresses) {
+		_, err = db.UserCollection.UpdateOne(c, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"addresses": user.Addresses}})
+		if err != nil {
+			log.Printf("❌ Failed to store address IDs for %s: %v", user.ID.Hex(), err)
+		}
+	  }
+
 	  c.AbortWithStatusJSON(http.StatusOK , gin.H{
 		"message" : "User Found successfully",
 		"userInfo" : user ,
@@ -186,6 +197,18 @@ func GetCurrentUser(c *gin.Context){
 	  return ;
 }
 
+// assignAddressIDs gives every address without an ID a new one and reports whether any changed
+func assignAddressIDs(addresses []dto.Address) bool {
+	changed := false
+	for i := range addresses {
+		if addresses[i].ID.IsZero() {
+			addresses[i].ID = primitive.NewObjectID()
+			changed = true
+		}
+	}
+	return changed
+}
+
 func Logout(c *gin.Context){
 	tokenInterface , exists := c.Get("token") ;
 	token := tokenInterface.(string)
@@ -3,6 +3,7 @@ package dto
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type Address struct {
+	ID         primitive.ObjectID `bson:"id,omitempty" json:"id"`
 	Street     string `json:"street" `
 	City       string `json:"city" `
 	State      string `json:"state" `
@@ -4,6 +4,8 @@ import "go.mongodb.org/mongo-driver/bson/primitive"
 
 
 type Address struct {
+	// ID lets checkout pick an address from the book
+	ID			primitive.ObjectID `bson:"id,omitempty" json:"id"`
 	Street    	string `json:"street" binding:"required"`
 	City      	string `json:"city" binding:"required"`
 	State     	string `json:"state" binding:"required"`
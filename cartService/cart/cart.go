@@ -2,8 +2,12 @@ package cart
 
 import (
 	"log"
+	"supernova/cartService/cart/src/broker"
+	cartcontroller "supernova/cartService/cart/src/cartController"
 	cartroutes "supernova/cartService/cart/src/cartRoutes"
 	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/jwtutils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -16,7 +20,16 @@ func SetupCartApp(router *gin.Engine) {
 		log.Print("Error loading .env file")
 	}
 
+	jwtutils.CheckGuestSecret()
 	db.InitDB()
+	broker.Connect()
+	broker.ConsumeQueues(map[string]broker.MessageHandler{
+		"ProductEventsCart": cartcontroller.HandleProductEvent,
+		"CouponRelease":     cartcontroller.HandleCouponRelease,
+		"CartClear":         cartcontroller.HandleCartClear,
+	})
+
+	go cartcontroller.StartAbandonedCartSweeper(time.Hour)
 
 	cartroutes.SetupCartRoutes(router)
 
@@ -0,0 +1,143 @@
+package cartcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"supernova/cartService/cart/src/broker"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/dto"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultAbandonAfter = 24 * time.Hour
+	defaultExpireAfter  = 90 * 24 * time.Hour
+)
+
+// StartAbandonedCartSweeper reminds signed-in shoppers about carts left idle
+// longer than CART_ABANDON_AFTER and deletes carts nobody has touched for
+// CART_EXPIRE_AFTER
+func StartAbandonedCartSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		remindAbandonedCarts(now, now.Add(-durationFromEnv("CART_ABANDON_AFTER", defaultAbandonAfter)))
+		expireStaleCarts(now.Add(-durationFromEnv("CART_EXPIRE_AFTER", defaultExpireAfter)))
+	}
+}
+
+// remindAbandonedCarts claims idle carts one at a time by stamping
+// reminderSentAt, so two sweepers never remind the same cart twice
+func remindAbandonedCarts(now time.Time, idleBefore time.Time) {
+	carts := db.GetCartCollection()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var cart cartmodel.Cart
+		err := carts.FindOneAndUpdate(ctx, bson.M{
+			"userId":    bson.M{"$type": "objectId"},
+			"email":     bson.M{"$type": "string", "$ne": ""},
+			"items.0":   bson.M{"$exists": true},
+			"updatedAt": bson.M{"$lt": idleBefore},
+			"$or": bson.A{
+				bson.M{"reminderSentAt": bson.M{"$exists": false}},
+				bson.M{"$expr": bson.M{"$lt": bson.A{"$reminderSentAt", "$updatedAt"}}},
+			},
+		}, bson.M{"$set": bson.M{"reminderSentAt": now}}).Decode(&cart)
+		cancel()
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			log.Printf("❌ cartService abandoned cart sweep failed: %v", err)
+			return
+		}
+		publishCartAbandoned(cart, now)
+	}
+}
+
+func publishCartAbandoned(cart cartmodel.Cart, now time.Time) {
+	if err := applyCurrentPrices(&cart); err != nil {
+		log.Printf("⚠️ cartService reminding with stored prices, quote failed: %v", err)
+	}
+
+	event := dto.CartAbandonedEvent{
+		EventID:      fmt.Sprintf("%s-%d", cart.ID.Hex(), cart.UpdatedAt.Unix()),
+		CartID:       cart.ID.Hex(),
+		UserID:       cart.UserID.Hex(),
+		ReceiverMail: cart.Email,
+		Items:        make([]dto.AbandonedItem, 0, len(cart.Items)),
+		IdleSince:    cart.UpdatedAt,
+		AbandonedAt:  now,
+	}
+	if storefront := os.Getenv("STOREFRONT_URL"); storefront != "" {
+		event.ReturnURL = strings.TrimRight(storefront, "/") + "/cart"
+	}
+	for _, item := range cart.Items {
+		if item.Unavailable {
+			continue
+		}
+		event.Items = append(event.Items, dto.AbandonedItem{
+			ProductID: item.ProductID.Hex(),
+			Title:     item.Title,
+			SellerID:  item.SellerID,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+		})
+	}
+	// nothing left that could be bought, so there is nothing to come back for
+	if len(event.Items) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("❌ cartService failed to encode CartAbandoned: %v", err)
+		return
+	}
+	if err := broker.PublishJSON("CartAbandoned", body); err != nil {
+		log.Printf("❌ cartService failed to publish CartAbandoned: %v", err)
+	}
+
+	event.ReceiverMail = ""
+	body, err = json.Marshal(event)
+	if err != nil {
+		log.Printf("❌ cartService failed to encode CartAbandonedDashboard: %v", err)
+		return
+	}
+	if err := broker.PublishJSON("CartAbandonedDashboard", body); err != nil {
+		log.Printf("❌ cartService failed to publish CartAbandonedDashboard: %v", err)
+	}
+}
+
+// expireStaleCarts deletes user carts idle since before cutoff; guest carts
+// are removed sooner by their TTL index
+func expireStaleCarts(cutoff time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	res, err := db.GetCartCollection().DeleteMany(ctx, bson.M{"updatedAt": bson.M{"$lt": cutoff}})
+	if err != nil {
+		log.Printf("❌ cartService failed to expire stale carts: %v", err)
+		return
+	}
+	if res.DeletedCount > 0 {
+		log.Printf("🧹 cartService expired %d stale carts", res.DeletedCount)
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
@@ -2,16 +2,36 @@ package cartcontroller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"supernova/cartService/cart/src/cartModel"
 	"supernova/cartService/cart/src/db"
 	"supernova/cartService/cart/src/dto"
+	"supernova/shared/pricing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxCartRetries bounds how often AddItemToCart re-reads a cart that
+// another request changed between its read and its write
+const maxCartRetries = 5
+
+var updatedCart = options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+var (
+	errCartNotFound = errors.New("cart not found")
+	errLineNotFound = errors.New("item not found")
+	// errCartChanged means the cart moved past the version the change was made against
+	errCartChanged = errors.New("cart changed")
+	errCartStore   = errors.New("cart store failed")
 )
 
 func AddItemToCart(c *gin.Context) {
@@ -21,14 +41,9 @@ func AddItemToCart(c *gin.Context) {
 		return
 	}
 
-	userId, exists := c.Get("UserID")
-	if !exists {
-		c.JSON(500, gin.H{"error": "UserID not found in context"})
-		return
-	}
-	userObjectID, err := primitive.ObjectIDFromHex(userId.(string))
+	owner, err := ownerFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	productObjectID, err := primitive.ObjectIDFromHex(item.ProductID)
@@ -36,27 +51,57 @@ func AddItemToCart(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
 		return
 	}
-	item.ProductID = productObjectID.Hex()
 
 	ctx, cancle := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancle()
 
-	res := db.GetCartCollection().FindOne(ctx, bson.M{"userId": userObjectID})
-	cart, err := res.Raw()
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			newCart := cartmodel.Cart{}
-			newCart.UserID = userObjectID
-			newCart.Items = []cartmodel.Item{
-				{
-					productObjectID,
-					item.Price,
-					item.Quantity,
-				},
+	carts := db.GetCartCollection()
+	// stock is checked against the quantity the line will end up with, which
+	// depends on the cart as read; the write only lands if the cart is still
+	// at that version, otherwise it is read again
+	for attempt := 0; attempt < maxCartRetries; attempt++ {
+		var existingCart cartmodel.Cart
+		err := carts.FindOne(ctx, owner.filter()).Decode(&existingCart)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		cartExists := err == nil
+
+		quantity := item.Quantity
+		lineExists := false
+		for _, cartItem := range existingCart.Items {
+			if cartItem.ProductID == productObjectID {
+				quantity += cartItem.Quantity
+				lineExists = true
+				break
+			}
+		}
+		line, err := quoteProduct(productObjectID, quantity)
+		if err != nil {
+			respondQuoteError(c, err)
+			return
+		}
+
+		if !cartExists {
+			newCart := cartmodel.Cart{
+				Items: []cartmodel.Item{{
+					ProductID:  productObjectID,
+					Title:      line.Title,
+					Price:      line.UnitPrice,
+					AddedPrice: line.UnitPrice,
+					Quantity:   quantity,
+				}},
+				Version:   1,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			owner.stamp(&newCart)
+			_, err = carts.InsertOne(ctx, newCart)
+			if mongo.IsDuplicateKeyError(err) {
+				// another request created the cart first
+				continue
 			}
-			newCart.CreatedAt = time.Now()
-			newCart.UpdatedAt = time.Now()
-			_, err := db.GetCartCollection().InsertOne(ctx, newCart)
 			if err != nil {
 				c.JSON(500, gin.H{"error": "Failed to create cart"})
 				return
@@ -67,173 +112,161 @@ func AddItemToCart(c *gin.Context) {
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-
-	}
 
-	var existingCart cartmodel.Cart
-	err = bson.Unmarshal(cart, &existingCart)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse cart data"})
-		return
-	}
+		filter := atVersion(owner.filter(), existingCart.Version)
+		var update bson.M
+		if lineExists {
+			filter["items.productId"] = productObjectID
+			update = bson.M{
+				"$set": owner.touch(bson.M{
+					"items.$.quantity": quantity,
+					"items.$.title":    line.Title,
+					"items.$.price":    line.UnitPrice,
+				}),
+				"$inc": bson.M{"version": 1},
+			}
+		} else {
+			update = bson.M{
+				"$push": bson.M{"items": cartmodel.Item{
+					ProductID:  productObjectID,
+					Title:      line.Title,
+					Price:      line.UnitPrice,
+					AddedPrice: line.UnitPrice,
+					Quantity:   quantity,
+				}},
+				"$set": owner.touch(bson.M{}),
+				"$inc": bson.M{"version": 1},
+			}
+		}
 
-	itemExists := false
-	for i, cartItem := range existingCart.Items {
-		if cartItem.ProductID == productObjectID {
-			existingCart.Items[i].Quantity += item.Quantity
-			itemExists = true
-			break
+		var committed cartmodel.Cart
+		err = carts.FindOneAndUpdate(ctx, filter, update, updatedCart).Decode(&committed)
+		if err == mongo.ErrNoDocuments {
+			continue
 		}
-	}
-	if !itemExists {
-		existingCart.Items = append(existingCart.Items, cartmodel.Item{
-			ProductID: productObjectID,
-			Quantity:  item.Quantity,
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to update cart"})
+			return
+		}
+		c.JSON(200, gin.H{
+			"message": "Item added to cart",
+			"cart":    committed,
 		})
-	}
-	existingCart.UpdatedAt = time.Now()
-
-	_, err = db.GetCartCollection().UpdateOne(ctx, bson.M{"userId": userObjectID}, bson.M{"$set": existingCart})
-	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to update cart"})
 		return
 	}
-	c.JSON(200, gin.H{
-		"message": "Item added to cart",
-		"cart":    existingCart,
-	})
-	return
 
+	c.JSON(http.StatusConflict, gin.H{"error": "Cart is being changed by another request, please retry"})
 }
 
 func UpdateItemQuantity(c *gin.Context) {
-	var item cartmodel.Item
-
-	err := c.ShouldBind(&item)
-	if err != nil {
+	var item dto.ItemQuantity
+	if err := c.ShouldBindJSON(&item); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	userId, exists := c.Get("UserID")
-	if !exists {
-		c.JSON(500, gin.H{"error": "UserID not found in context"})
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	ctx, cancle := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancle()
-	res := db.GetCartCollection().FindOne(ctx, bson.M{"userId": userId})
-	cart, err := res.Raw()
+	productObjectID, err := primitive.ObjectIDFromHex(item.ProductID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(404, gin.H{"error": "Cart not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
 		return
 	}
-	var existingCart cartmodel.Cart
-	err = bson.Unmarshal(cart, &existingCart)
+
+	// the new quantity does not depend on the old one, so it is priced and
+	// checked first and then written in a single update
+	line, err := quoteProduct(productObjectID, item.Quantity)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse cart data"})
+		respondQuoteError(c, err)
 		return
 	}
-	itemExists := false
-	for i, cartItem := range existingCart.Items {
-		if cartItem.ProductID == item.ProductID {
-			existingCart.Items[i].Quantity = item.Quantity
-			itemExists = true
-			break
-		}
+
+	ctx, cancle := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancle()
+
+	filter := owner.filter()
+	filter["items.productId"] = productObjectID
+	if item.Version != nil {
+		atVersion(filter, *item.Version)
 	}
-	if !itemExists {
-		c.JSON(404, gin.H{"error": "Item not found in cart"})
+	var committed cartmodel.Cart
+	err = db.GetCartCollection().FindOneAndUpdate(ctx, filter, bson.M{
+		"$set": owner.touch(bson.M{
+			"items.$.quantity": item.Quantity,
+			"items.$.title":    line.Title,
+			"items.$.price":    line.UnitPrice,
+		}),
+		"$inc": bson.M{"version": 1},
+	}, updatedCart).Decode(&committed)
+	if err == mongo.ErrNoDocuments {
+		respondCartMiss(ctx, c, owner, productObjectID)
 		return
 	}
-	existingCart.UpdatedAt = time.Now()
-
-	_, err = db.GetCartCollection().UpdateOne(ctx, bson.M{"userId": userId}, bson.M{"$set": existingCart})
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to update cart"})
 		return
 	}
 	c.JSON(200, gin.H{
 		"message": "Item quantity updated",
-		"cart":    existingCart,
+		"cart":    committed,
 	})
-	return
 }
 
 func RemoveItemFromCart(c *gin.Context) {
-	var item cartmodel.Item
-	err := c.ShouldBind(&item)
-	if err != nil {
+	var item dto.RemoveItem
+	if err := c.ShouldBindJSON(&item); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	userId, exists := c.Get("UserID")
-	if !exists {
-		c.JSON(500, gin.H{"error": "UserID not found in context"})
-		return
-	}
-	ctx, cancle := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancle()
-	res := db.GetCartCollection().FindOne(ctx, bson.M{"userId": userId})
-	cart, err := res.Raw()
+	owner, err := ownerFromContext(c)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(404, gin.H{"error": "Cart not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	var existingCart cartmodel.Cart
-	err = bson.Unmarshal(cart, &existingCart)
+	productObjectID, err := primitive.ObjectIDFromHex(item.ProductID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse cart data"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
 		return
 	}
-	itemIndex := -1
-	for i, cartItem := range existingCart.Items {
-		if cartItem.ProductID == item.ProductID {
-			itemIndex = i
-			break
-		}
+	ctx, cancle := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancle()
+
+	filter := owner.filter()
+	filter["items.productId"] = productObjectID
+	if item.Version != nil {
+		atVersion(filter, *item.Version)
 	}
-	if itemIndex == -1 {
-		c.JSON(404, gin.H{"error": "Item not found in cart"})
+	var committed cartmodel.Cart
+	err = db.GetCartCollection().FindOneAndUpdate(ctx, filter, bson.M{
+		"$pull": bson.M{"items": bson.M{"productId": productObjectID}},
+		"$set":  owner.touch(bson.M{}),
+		"$inc":  bson.M{"version": 1},
+	}, updatedCart).Decode(&committed)
+	if err == mongo.ErrNoDocuments {
+		respondCartMiss(ctx, c, owner, productObjectID)
 		return
 	}
-	existingCart.Items = append(existingCart.Items[:itemIndex], existingCart.Items[itemIndex+1:]...)
-	existingCart.UpdatedAt = time.Now()
-	_, err = db.GetCartCollection().UpdateOne(ctx, bson.M{"userId": userId}, bson.M{"$set": existingCart})
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to update cart"})
 		return
 	}
 	c.JSON(200, gin.H{
 		"message": "Item removed from cart",
-		"cart":    existingCart,
+		"cart":    committed,
 	})
-	return
 }
 
 func GetCart(c *gin.Context) {
-	userId, exists := c.Get("UserID")
-	if !exists {
-		c.JSON(500, gin.H{"error": "UserID not found in context"})
-		return
-	}
-	userObjectID, err := primitive.ObjectIDFromHex(userId.(string))
+	owner, err := ownerFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	ctx, cancle := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancle()
-	res := db.GetCartCollection().FindOne(ctx, bson.M{"userId": userObjectID})
+	res := db.GetCartCollection().FindOne(ctx, owner.filter())
 	cart, err := res.Raw()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -249,26 +282,44 @@ func GetCart(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse cart data"})
 		return
 	}
-	c.JSON(200, gin.H{
+	// the coupon and totals are only worked out from live prices, never stored ones
+	var discount *dto.CouponDiscount
+	var summary *pricing.Summary
+	if err := applyCurrentPrices(&existingCart); err != nil {
+		log.Printf("⚠️ cartService serving stored prices, quote failed: %v", err)
+	} else {
+		discount = cartCouponDiscount(ctx, owner, existingCart)
+		if priced, err := cartSummary(existingCart, discount, c.GetString("Token")); err != nil {
+			log.Printf("⚠️ cartService serving cart without totals: %v", err)
+		} else {
+			summary = &priced
+		}
+	}
+	if err := priceItems(existingCart.SavedForLater); err != nil {
+		log.Printf("⚠️ cartService serving stored prices for saved items, quote failed: %v", err)
+	}
+	response := gin.H{
 		"cart": existingCart,
-	})
+	}
+	if discount != nil {
+		response["discount"] = discount
+	}
+	if summary != nil {
+		response["summary"] = summary
+	}
+	c.JSON(200, response)
 	return
 }
 
 func ClearCart(c *gin.Context) {
-	userId, exists := c.Get("UserID")
-	if !exists {
-		c.JSON(500, gin.H{"error": "UserID not found in context"})
-		return
-	}
-	userObjectID, err := primitive.ObjectIDFromHex(userId.(string))
+	owner, err := ownerFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	ctx, cancle := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancle()
-	_, err = db.GetCartCollection().DeleteOne(ctx, bson.M{"userId": userObjectID})
+	err = clearCartItems(ctx, owner.filter(), owner.touch(bson.M{}))
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to clear cart"})
 		return
@@ -278,3 +329,169 @@ func ClearCart(c *gin.Context) {
 	})
 	return
 }
+
+// HandleCartClear consumes the CartClear queue, the order service's fallback
+// for when it could not clear the cart right after checkout. A cart changed
+// since the order was placed is left alone rather than losing newer items.
+func HandleCartClear(body []byte) {
+	var event dto.CartClearEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("❌ cartService invalid CartClear message: %v", err)
+		return
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(event.UserID)
+	if err != nil {
+		log.Printf("❌ cartService invalid user ID in CartClear: %s", event.UserID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = clearCartItems(ctx,
+		bson.M{"userId": userObjectID, "updatedAt": bson.M{"$lte": event.PlacedAt}},
+		bson.M{"updatedAt": time.Now()},
+	)
+	if err != nil {
+		log.Printf("❌ cartService failed to clear cart for order %s: %v", event.OrderID, err)
+	}
+}
+
+// clearCartItems empties the cart matching filter. A cart with saved for
+// later lines keeps them; only its items and coupon go.
+func clearCartItems(ctx context.Context, filter bson.M, set bson.M) error {
+	deleteFilter := bson.M{"savedForLater.0": bson.M{"$exists": false}}
+	for key, value := range filter {
+		deleteFilter[key] = value
+	}
+	res, err := db.GetCartCollection().DeleteOne(ctx, deleteFilter)
+	if err != nil || res.DeletedCount > 0 {
+		return err
+	}
+	set["items"] = []cartmodel.Item{}
+	_, err = db.GetCartCollection().UpdateOne(ctx, filter, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"couponCode": ""},
+		"$inc":   bson.M{"version": 1},
+	})
+	return err
+}
+
+// respondCartMiss explains why a conditional cart update matched nothing:
+// no cart, no such line, or a version the cart has already moved past
+func respondCartMiss(ctx context.Context, c *gin.Context, owner cartOwner, productID primitive.ObjectID) {
+	var existingCart cartmodel.Cart
+	err := db.GetCartCollection().FindOne(ctx, owner.filter()).Decode(&existingCart)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(404, gin.H{"error": "Cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, cartItem := range existingCart.Items {
+		if cartItem.ProductID == productID {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Cart has changed since that version",
+				"cart":  existingCart,
+			})
+			return
+		}
+	}
+	c.JSON(404, gin.H{"error": "Item not found in cart"})
+}
+
+// respondQuoteError maps a failed product lookup to the status the client should see
+func respondQuoteError(c *gin.Context, err error) {
+	var stockErr *insufficientStockError
+	switch {
+	case errors.Is(err, errProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	case errors.As(err, &stockErr):
+		c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock", "available": stockErr.Available})
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	}
+}
+
+// changeCart applies change to the owner's cart and writes its items and
+// saved for later list back in one update, as long as nobody else changed
+// the cart in between. With a version from the client the cart must also
+// still be at that version; without one a lost race is retried.
+func changeCart(ctx context.Context, owner cartOwner, version *int64, change func(cart *cartmodel.Cart) error) (cartmodel.Cart, error) {
+	carts := db.GetCartCollection()
+	for attempt := 0; attempt < maxCartRetries; attempt++ {
+		var existingCart cartmodel.Cart
+		err := carts.FindOne(ctx, owner.filter()).Decode(&existingCart)
+		if err == mongo.ErrNoDocuments {
+			return existingCart, errCartNotFound
+		}
+		if err != nil {
+			return existingCart, fmt.Errorf("%w: %v", errCartStore, err)
+		}
+		if version != nil && existingCart.Version != *version {
+			return existingCart, errCartChanged
+		}
+		if err := change(&existingCart); err != nil {
+			return existingCart, err
+		}
+		if existingCart.Items == nil {
+			existingCart.Items = []cartmodel.Item{}
+		}
+		if existingCart.SavedForLater == nil {
+			existingCart.SavedForLater = []cartmodel.Item{}
+		}
+
+		var committed cartmodel.Cart
+		err = carts.FindOneAndUpdate(ctx, atVersion(owner.filter(), existingCart.Version), bson.M{
+			"$set": owner.touch(bson.M{
+				"items":         existingCart.Items,
+				"savedForLater": existingCart.SavedForLater,
+			}),
+			"$inc": bson.M{"version": 1},
+		}, updatedCart).Decode(&committed)
+		if err == mongo.ErrNoDocuments {
+			if version != nil {
+				return existingCart, errCartChanged
+			}
+			continue
+		}
+		if err != nil {
+			return existingCart, fmt.Errorf("%w: %v", errCartStore, err)
+		}
+		return committed, nil
+	}
+	return cartmodel.Cart{}, errCartChanged
+}
+
+// findLine returns the index of productID's line in items, or -1
+func findLine(items []cartmodel.Item, productID primitive.ObjectID) int {
+	for i, item := range items {
+		if item.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+// respondCartError maps an error from changeCart, changeNamedCart or
+// mergeIntoCart to the status the client should see; anything else is a
+// product lookup failure
+func respondCartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errCartNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+	case errors.Is(err, errNamedCartNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Named cart not found"})
+	case errors.Is(err, errLineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+	case errors.Is(err, errCartChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": "Cart is being changed by another request, please retry"})
+	case errors.Is(err, errCartStore):
+		log.Printf("❌ cartService %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+	default:
+		respondQuoteError(c, err)
+	}
+}
@@ -0,0 +1,307 @@
+package cartcontroller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/dto"
+	"supernova/shared/money"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var initDB sync.Once
+
+// setupCartTest connects to the Mongo at MONGO_URI and points the product
+// client at a fake product service with plenty of stock at 19.99 INR
+func setupCartTest(t *testing.T) {
+	t.Helper()
+	if os.Getenv("MONGO_URI") == "" {
+		t.Skip("MONGO_URI not set")
+	}
+	gin.SetMode(gin.TestMode)
+	initDB.Do(db.InitDB)
+
+	products := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var quoteReq dto.QuoteRequest
+		if err := json.NewDecoder(r.Body).Decode(&quoteReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		quote := dto.QuoteResponse{Missing: []string{}, QuotedAt: time.Now()}
+		for _, item := range quoteReq.Items {
+			price := money.New(1999, money.INR)
+			quote.Items = append(quote.Items, dto.QuoteLine{
+				ProductID:     item.ProductID,
+				Title:         "Test product",
+				Quantity:      item.Quantity,
+				Stock:         1000,
+				OriginalPrice: price,
+				UnitPrice:     price,
+			})
+		}
+		json.NewEncoder(w).Encode(quote)
+	}))
+	t.Cleanup(products.Close)
+	t.Setenv("PRODUCT_SERVICE_URL", products.URL)
+}
+
+func addItem(userID primitive.ObjectID, productID primitive.ObjectID, quantity int) int {
+	body, _ := json.Marshal(dto.Item{ProductID: productID.Hex(), Quantity: quantity})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/cart/add", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("UserID", userID.Hex())
+	AddItemToCart(c)
+	return w.Code
+}
+
+// mutateItem calls a cart handler taking a productId body, as the cart's owner
+func mutateItem(handler gin.HandlerFunc, userID primitive.ObjectID, body interface{}) int {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/api/cart/item", bytes.NewReader(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("UserID", userID.Hex())
+	handler(c)
+	return w.Code
+}
+
+func loadCart(t *testing.T, userID primitive.ObjectID) cartmodel.Cart {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var cart cartmodel.Cart
+	if err := db.GetCartCollection().FindOne(ctx, bson.M{"userId": userID}).Decode(&cart); err != nil {
+		t.Fatalf("load cart: %v", err)
+	}
+	return cart
+}
+
+func deleteCart(userID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db.GetCartCollection().DeleteOne(ctx, bson.M{"userId": userID})
+}
+
+// Parallel adds to one cart must neither lose an increment nor skip a version:
+// every add that reports success is in the quantity and bumped the version once
+func TestAddItemToCartConcurrent(t *testing.T) {
+	setupCartTest(t)
+
+	userID := primitive.NewObjectID()
+	productID := primitive.NewObjectID()
+	t.Cleanup(func() { deleteCart(userID) })
+
+	const adds = 8
+	codes := make(chan int, adds)
+	var wg sync.WaitGroup
+	for i := 0; i < adds; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- addItem(userID, productID, 1)
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	succeeded := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusConflict:
+			// retries ran out; the client is told to retry and nothing was written
+		default:
+			t.Errorf("AddItemToCart answered %d", code)
+		}
+	}
+	if succeeded == 0 {
+		t.Fatal("no add succeeded")
+	}
+
+	cart := loadCart(t, userID)
+	if len(cart.Items) != 1 {
+		t.Fatalf("cart has %d lines, want 1", len(cart.Items))
+	}
+	if cart.Items[0].Quantity != succeeded {
+		t.Errorf("quantity = %d, want %d", cart.Items[0].Quantity, succeeded)
+	}
+	if cart.Version != int64(succeeded) {
+		t.Errorf("version = %d, want %d", cart.Version, succeeded)
+	}
+}
+
+// A price change from the product service is a cart write like any other:
+// it bumps the version once, and a replayed event changes nothing
+func TestProductUpdateBumpsCartVersion(t *testing.T) {
+	setupCartTest(t)
+
+	userID := primitive.NewObjectID()
+	productID := primitive.NewObjectID()
+	t.Cleanup(func() { deleteCart(userID) })
+
+	if code := addItem(userID, productID, 2); code != http.StatusOK {
+		t.Fatalf("AddItemToCart answered %d", code)
+	}
+	before := loadCart(t, userID)
+
+	event, _ := json.Marshal(dto.ProductEvent{
+		Type:      "ProductUpdated",
+		ProductID: productID,
+		Version:   5,
+		Product:   &dto.ProductSnapshot{Title: "Test product", Price: money.New(1499, money.INR), Stock: 1000},
+	})
+	HandleProductEvent(event)
+	HandleProductEvent(event)
+
+	after := loadCart(t, userID)
+	if after.Items[0].Price.Amount != 1499 {
+		t.Errorf("price = %v, want 14.99 INR", after.Items[0].Price)
+	}
+	if after.Version != before.Version+1 {
+		t.Errorf("version = %d, want %d", after.Version, before.Version+1)
+	}
+
+	// later writes carry on from the bumped version
+	if code := addItem(userID, productID, 1); code != http.StatusOK {
+		t.Fatalf("AddItemToCart answered %d", code)
+	}
+	final := loadCart(t, userID)
+	if final.Items[0].Quantity != 3 || final.Version != after.Version+1 {
+		t.Errorf("after add: quantity %d version %d, want 3 and %d", final.Items[0].Quantity, final.Version, after.Version+1)
+	}
+}
+
+// fillCart adds one unit of n new products and returns them in order
+func fillCart(t *testing.T, userID primitive.ObjectID, n int) []primitive.ObjectID {
+	t.Helper()
+	products := make([]primitive.ObjectID, n)
+	for i := range products {
+		products[i] = primitive.NewObjectID()
+		if code := addItem(userID, products[i], 1); code != http.StatusOK {
+			t.Fatalf("AddItemToCart answered %d", code)
+		}
+	}
+	return products
+}
+
+// Quantity updates and removals of different lines racing on one cart must
+// all land, each bumping the version exactly once
+func TestUpdateAndRemoveConcurrent(t *testing.T) {
+	setupCartTest(t)
+
+	userID := primitive.NewObjectID()
+	t.Cleanup(func() { deleteCart(userID) })
+	products := fillCart(t, userID, 8)
+	before := loadCart(t, userID)
+
+	codes := make(chan int, len(products))
+	var wg sync.WaitGroup
+	for i, productID := range products {
+		wg.Add(1)
+		go func(i int, productID primitive.ObjectID) {
+			defer wg.Done()
+			if i%2 == 0 {
+				codes <- mutateItem(UpdateItemQuantity, userID, dto.ItemQuantity{ProductID: productID.Hex(), Quantity: 5})
+			} else {
+				codes <- mutateItem(RemoveItemFromCart, userID, dto.RemoveItem{ProductID: productID.Hex()})
+			}
+		}(i, productID)
+	}
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("mutation answered %d", code)
+		}
+	}
+
+	after := loadCart(t, userID)
+	if after.Version != before.Version+int64(len(products)) {
+		t.Errorf("version = %d, want %d", after.Version, before.Version+int64(len(products)))
+	}
+	quantities := make(map[primitive.ObjectID]int)
+	for _, item := range after.Items {
+		quantities[item.ProductID] = item.Quantity
+	}
+	for i, productID := range products {
+		quantity, ok := quantities[productID]
+		switch {
+		case i%2 == 0 && quantity != 5:
+			t.Errorf("product %d quantity = %d, want 5", i, quantity)
+		case i%2 == 1 && ok:
+			t.Errorf("product %d still in the cart", i)
+		}
+	}
+}
+
+// Racing mutations that all expect the same version: exactly one wins and
+// the rest are told the cart changed instead of overwriting it
+func TestUpdateAndRemoveConcurrentAtVersion(t *testing.T) {
+	setupCartTest(t)
+
+	userID := primitive.NewObjectID()
+	t.Cleanup(func() { deleteCart(userID) })
+	products := fillCart(t, userID, 8)
+	before := loadCart(t, userID)
+	version := before.Version
+
+	codes := make(chan int, len(products))
+	var wg sync.WaitGroup
+	for i, productID := range products {
+		wg.Add(1)
+		go func(i int, productID primitive.ObjectID) {
+			defer wg.Done()
+			if i%2 == 0 {
+				codes <- mutateItem(UpdateItemQuantity, userID, dto.ItemQuantity{ProductID: productID.Hex(), Quantity: 5, Version: &version})
+			} else {
+				codes <- mutateItem(RemoveItemFromCart, userID, dto.RemoveItem{ProductID: productID.Hex(), Version: &version})
+			}
+		}(i, productID)
+	}
+	wg.Wait()
+	close(codes)
+
+	succeeded := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusConflict:
+		default:
+			t.Errorf("mutation answered %d", code)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d mutations succeeded, want 1", succeeded)
+	}
+
+	after := loadCart(t, userID)
+	if after.Version != version+1 {
+		t.Errorf("version = %d, want %d", after.Version, version+1)
+	}
+	changed := len(products) - len(after.Items)
+	for _, item := range after.Items {
+		if item.Quantity != 1 {
+			changed++
+		}
+	}
+	if changed != 1 {
+		t.Errorf("%d lines changed, want 1", changed)
+	}
+}
@@ -0,0 +1,515 @@
+package cartcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/dto"
+	"supernova/shared/money"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errCouponNotFound = errors.New("Coupon not found")
+
+// CreateCoupon lets an admin create any coupon and a seller create coupons
+// for their own products
+func CreateCoupon(c *gin.Context) {
+	var couponDTO dto.CouponDTO
+	if err := c.ShouldBindJSON(&couponDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if couponDTO.Type == string(cartmodel.CouponPercentage) && couponDTO.Value > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A percentage coupon cannot exceed 100"})
+		return
+	}
+
+	now := time.Now()
+	currency := money.Currency(couponDTO.Currency)
+	coupon := cartmodel.Coupon{
+		ID:           primitive.NewObjectID(),
+		Code:         normalizeCouponCode(couponDTO.Code),
+		Type:         cartmodel.CouponType(couponDTO.Type),
+		Currency:     currency,
+		MinSpend:     money.FromMajor(couponDTO.MinSpend, currency),
+		MaxDiscount:  money.FromMajor(couponDTO.MaxDiscount, currency),
+		UsageLimit:   couponDTO.UsageLimit,
+		PerUserLimit: couponDTO.PerUserLimit,
+		SellerID:     couponDTO.SellerID,
+		Category:     strings.TrimSpace(couponDTO.Category),
+		StartsAt:     now,
+		ExpiresAt:    couponDTO.ExpiresAt,
+		Active:       true,
+		CreatedBy:    c.GetString("UserID"),
+		CreatedAt:    now,
+	}
+	if coupon.Type == cartmodel.CouponPercentage {
+		coupon.Percent = couponDTO.Value
+	} else {
+		coupon.Amount = money.FromMajor(couponDTO.Value, currency)
+	}
+	if couponDTO.StartsAt != nil {
+		coupon.StartsAt = *couponDTO.StartsAt
+	}
+	if coupon.ExpiresAt != nil && !coupon.ExpiresAt.After(coupon.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresAt must be after startsAt"})
+		return
+	}
+	// a seller's coupon can only ever discount their own products
+	if c.GetString("Role") != "admin" {
+		coupon.SellerID = c.GetString("UserID")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := db.GetCouponCollection().InsertOne(ctx, coupon)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Coupon code already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create coupon"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Coupon created successfully",
+		"coupon":  coupon,
+	})
+}
+
+// GetCoupons lists every coupon for an admin and a seller's own coupons otherwise
+func GetCoupons(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if c.GetString("Role") != "admin" {
+		filter["createdBy"] = c.GetString("UserID")
+	}
+	cursor, err := db.GetCouponCollection().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	coupons := []cartmodel.Coupon{}
+	if err := cursor.All(ctx, &coupons); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"coupons": coupons})
+}
+
+// DeactivateCoupon stops a coupon from being applied or redeemed; its history is kept
+func DeactivateCoupon(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"code": normalizeCouponCode(c.Param("code"))}
+	if c.GetString("Role") != "admin" {
+		filter["createdBy"] = c.GetString("UserID")
+	}
+	res, err := db.GetCouponCollection().UpdateOne(ctx, filter, bson.M{"$set": bson.M{"active": false}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if res.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Coupon not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Coupon deactivated"})
+}
+
+// ApplyCoupon checks a code against the cart as priced now and keeps it on
+// the cart; the response carries the discount breakdown
+func ApplyCoupon(c *gin.Context) {
+	var applyDTO dto.ApplyCouponDTO
+	if err := c.ShouldBindJSON(&applyDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coupon, err := findCoupon(ctx, applyDTO.Code)
+	if err == errCouponNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Coupon not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existingCart cartmodel.Cart
+	err = db.GetCartCollection().FindOne(ctx, owner.filter()).Decode(&existingCart)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := applyCurrentPrices(&existingCart); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	discount := couponDiscountFor(ctx, owner, coupon, existingCart.Items)
+	if !discount.Valid {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": discount.Reason, "discount": discount})
+		return
+	}
+
+	var committed cartmodel.Cart
+	err = db.GetCartCollection().FindOneAndUpdate(ctx, owner.filter(), bson.M{
+		"$set": owner.touch(bson.M{"couponCode": coupon.Code}),
+		"$inc": bson.M{"version": 1},
+	}, updatedCart).Decode(&committed)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply coupon"})
+		return
+	}
+	if err := applyCurrentPrices(&committed); err != nil {
+		log.Printf("⚠️ cartService serving stored prices, quote failed: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Coupon applied",
+		"cart":     committed,
+		"discount": discount,
+	})
+}
+
+func RemoveCoupon(c *gin.Context) {
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var committed cartmodel.Cart
+	err = db.GetCartCollection().FindOneAndUpdate(ctx, owner.filter(), bson.M{
+		"$unset": bson.M{"couponCode": ""},
+		"$set":   owner.touch(bson.M{}),
+		"$inc":   bson.M{"version": 1},
+	}, updatedCart).Decode(&committed)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove coupon"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Coupon removed",
+		"cart":    committed,
+	})
+}
+
+// RedeemCoupon is called by the order service while it places an order. The
+// cart's coupon is checked again against live prices and then counted
+// against the per-user and global limits; a limit that is already used up
+// fails the redemption rather than being exceeded. Redeeming twice for the
+// same order is a no-op.
+func RedeemCoupon(c *gin.Context) {
+	var redeemDTO dto.RedeemCouponDTO
+	if err := c.ShouldBindJSON(&redeemDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, err := ownerFromContext(c)
+	if err != nil || owner.isGuest() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var existingCart cartmodel.Cart
+	err = db.GetCartCollection().FindOne(ctx, owner.filter()).Decode(&existingCart)
+	if err == mongo.ErrNoDocuments || (err == nil && existingCart.CouponCode == "") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No coupon applied to the cart"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	coupon, err := findCoupon(ctx, existingCart.CouponCode)
+	if err == errCouponNotFound {
+		c.JSON(http.StatusConflict, gin.H{"error": "Coupon no longer exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := applyCurrentPrices(&existingCart); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	usages := db.GetCouponUsageCollection()
+	alreadyRedeemed, err := usages.CountDocuments(ctx, bson.M{
+		"couponId": coupon.ID,
+		"userId":   owner.userID,
+		"orderIds": redeemDTO.OrderID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if alreadyRedeemed > 0 {
+		c.JSON(http.StatusOK, gin.H{"discount": evaluateCoupon(coupon, existingCart.Items, time.Now())})
+		return
+	}
+
+	discount := couponDiscountFor(ctx, owner, coupon, existingCart.Items)
+	if !discount.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": discount.Reason, "discount": discount})
+		return
+	}
+
+	// the unique couponId+userId index turns a usage already at the limit into a duplicate key
+	userFilter := bson.M{"couponId": coupon.ID, "userId": owner.userID}
+	if coupon.PerUserLimit > 0 {
+		userFilter["count"] = bson.M{"$lt": coupon.PerUserLimit}
+	}
+	_, err = usages.UpdateOne(ctx, userFilter, bson.M{
+		"$inc":  bson.M{"count": 1},
+		"$push": bson.M{"orderIds": redeemDTO.OrderID},
+	}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already used this coupon"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem coupon"})
+		return
+	}
+
+	globalFilter := bson.M{"_id": coupon.ID, "active": true}
+	if coupon.UsageLimit > 0 {
+		globalFilter["usedCount"] = bson.M{"$lt": coupon.UsageLimit}
+	}
+	res, err := db.GetCouponCollection().UpdateOne(ctx, globalFilter, bson.M{"$inc": bson.M{"usedCount": 1}})
+	if err != nil || res.MatchedCount == 0 {
+		if _, undoErr := usages.UpdateOne(ctx,
+			bson.M{"couponId": coupon.ID, "userId": owner.userID, "orderIds": redeemDTO.OrderID},
+			bson.M{"$inc": bson.M{"count": -1}, "$pull": bson.M{"orderIds": redeemDTO.OrderID}},
+		); undoErr != nil {
+			log.Printf("❌ cartService failed to undo coupon usage for order %s: %v", redeemDTO.OrderID, undoErr)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem coupon"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Coupon has been fully redeemed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"discount": discount})
+}
+
+// HandleCouponRelease consumes the CouponRelease queue and gives back a
+// redemption whose order failed or was cancelled
+func HandleCouponRelease(body []byte) {
+	var event dto.CouponReleaseEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("❌ cartService invalid coupon release: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// pulling the order ID in the same update makes a repeated release a no-op
+	var usage cartmodel.CouponUsage
+	err := db.GetCouponUsageCollection().FindOneAndUpdate(ctx,
+		bson.M{"orderIds": event.OrderID},
+		bson.M{"$inc": bson.M{"count": -1}, "$pull": bson.M{"orderIds": event.OrderID}},
+	).Decode(&usage)
+	if err == mongo.ErrNoDocuments {
+		return
+	}
+	if err != nil {
+		log.Printf("❌ cartService failed to release coupon for order %s: %v", event.OrderID, err)
+		return
+	}
+	_, err = db.GetCouponCollection().UpdateOne(ctx,
+		bson.M{"_id": usage.CouponID},
+		bson.M{"$inc": bson.M{"usedCount": -1}},
+	)
+	if err != nil {
+		log.Printf("❌ cartService failed to release coupon for order %s: %v", event.OrderID, err)
+		return
+	}
+	log.Printf("✅ cartService released coupon for order %s (%s)", event.OrderID, event.Reason)
+}
+
+// cartCouponDiscount is the breakdown shown with a cart, or nil if it has no coupon
+func cartCouponDiscount(ctx context.Context, owner cartOwner, cart cartmodel.Cart) *dto.CouponDiscount {
+	if cart.CouponCode == "" {
+		return nil
+	}
+	coupon, err := findCoupon(ctx, cart.CouponCode)
+	if err == errCouponNotFound {
+		return &dto.CouponDiscount{Code: cart.CouponCode, Reason: "Coupon no longer exists", Lines: []dto.LineDiscount{}}
+	}
+	if err != nil {
+		log.Printf("⚠️ cartService failed to load coupon %s: %v", cart.CouponCode, err)
+		return nil
+	}
+	discount := couponDiscountFor(ctx, owner, coupon, cart.Items)
+	return &discount
+}
+
+// couponDiscountFor evaluates the coupon and, for a signed-in user, their own usage limit
+func couponDiscountFor(ctx context.Context, owner cartOwner, coupon cartmodel.Coupon, items []cartmodel.Item) dto.CouponDiscount {
+	discount := evaluateCoupon(coupon, items, time.Now())
+	if !discount.Valid || owner.isGuest() || coupon.PerUserLimit == 0 {
+		return discount
+	}
+	var usage cartmodel.CouponUsage
+	err := db.GetCouponUsageCollection().FindOne(ctx, bson.M{"couponId": coupon.ID, "userId": owner.userID}).Decode(&usage)
+	if err == nil && usage.Count >= coupon.PerUserLimit {
+		return invalidCoupon(coupon, "You have already used this coupon")
+	}
+	return discount
+}
+
+// evaluateCoupon works out what a coupon takes off cart lines that carry live
+// prices. Only available lines in the coupon's currency and scope count
+// towards the minimum spend and share the discount, in proportion to their
+// subtotal.
+func evaluateCoupon(coupon cartmodel.Coupon, items []cartmodel.Item, now time.Time) dto.CouponDiscount {
+	switch {
+	case !coupon.Active:
+		return invalidCoupon(coupon, "Coupon is no longer active")
+	case now.Before(coupon.StartsAt):
+		return invalidCoupon(coupon, "Coupon is not active yet")
+	case coupon.ExpiresAt != nil && !now.Before(*coupon.ExpiresAt):
+		return invalidCoupon(coupon, "Coupon has expired")
+	case coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit:
+		return invalidCoupon(coupon, "Coupon has been fully redeemed")
+	}
+
+	subtotal := money.Zero(coupon.Currency)
+	eligible := []cartmodel.Item{}
+	for _, item := range items {
+		if item.Unavailable || item.Price.Currency != coupon.Currency {
+			continue
+		}
+		if coupon.SellerID != "" && item.SellerID != coupon.SellerID {
+			continue
+		}
+		if coupon.Category != "" && !strings.EqualFold(item.Category, coupon.Category) {
+			continue
+		}
+		eligible = append(eligible, item)
+		subtotal, _ = subtotal.Add(item.Price.Mul(int64(item.Quantity)))
+	}
+	if len(eligible) == 0 || subtotal.IsZero() {
+		return invalidCoupon(coupon, "Coupon does not apply to any item in your cart")
+	}
+	if subtotal.Amount < coupon.MinSpend.Amount {
+		result := invalidCoupon(coupon, fmt.Sprintf("Spend at least %s on eligible items to use this coupon", money.New(coupon.MinSpend.Amount, coupon.Currency)))
+		result.EligibleSubtotal = subtotal
+		return result
+	}
+
+	var discount money.Money
+	if coupon.Type == cartmodel.CouponPercentage {
+		discount = subtotal.Percent(coupon.Percent)
+		if coupon.MaxDiscount.Amount > 0 && discount.Amount > coupon.MaxDiscount.Amount {
+			discount = money.New(coupon.MaxDiscount.Amount, coupon.Currency)
+		}
+	} else {
+		discount = money.New(coupon.Amount.Amount, coupon.Currency)
+	}
+	if discount.Amount > subtotal.Amount {
+		discount = subtotal
+	}
+
+	result := dto.CouponDiscount{
+		Code:             coupon.Code,
+		Type:             string(coupon.Type),
+		Valid:            true,
+		EligibleSubtotal: subtotal,
+		Discount:         discount,
+		Lines:            make([]dto.LineDiscount, 0, len(eligible)),
+	}
+	// the last line takes whatever rounding left over so the lines add up exactly
+	remaining := discount.Amount
+	for i, item := range eligible {
+		share := discount.Amount * item.Price.Mul(int64(item.Quantity)).Amount / subtotal.Amount
+		if i == len(eligible)-1 {
+			share = remaining
+		}
+		remaining -= share
+		result.Lines = append(result.Lines, dto.LineDiscount{
+			ProductID: item.ProductID.Hex(),
+			Discount:  money.New(share, coupon.Currency),
+		})
+	}
+	return result
+}
+
+func invalidCoupon(coupon cartmodel.Coupon, reason string) dto.CouponDiscount {
+	return dto.CouponDiscount{
+		Code:             coupon.Code,
+		Type:             string(coupon.Type),
+		Reason:           reason,
+		EligibleSubtotal: money.Zero(coupon.Currency),
+		Discount:         money.Zero(coupon.Currency),
+		Lines:            []dto.LineDiscount{},
+	}
+}
+
+func findCoupon(ctx context.Context, code string) (cartmodel.Coupon, error) {
+	var coupon cartmodel.Coupon
+	err := db.GetCouponCollection().FindOne(ctx, bson.M{"code": normalizeCouponCode(code)}).Decode(&coupon)
+	if err == mongo.ErrNoDocuments {
+		return coupon, errCouponNotFound
+	}
+	return coupon, err
+}
+
+// normalizeCouponCode makes codes case-insensitive
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
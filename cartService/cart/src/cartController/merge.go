@@ -0,0 +1,232 @@
+package cartcontroller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/dto"
+	"supernova/cartService/cart/src/jwtutils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MergeGuestCart moves the caller's guest cart into their user cart after
+// login. Quantities of products in both are summed, every merged line is
+// capped by current stock, and lines that could not be merged as asked are
+// returned as conflicts.
+func MergeGuestCart(c *gin.Context) {
+	owner, err := ownerFromContext(c)
+	if err != nil || owner.isGuest() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	cookie, err := c.Cookie(jwtutils.GuestCookieName)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "No guest cart to merge", "conflicts": []dto.MergeConflict{}})
+		return
+	}
+	guestID, err := jwtutils.ParseGuestToken(cookie)
+	if err != nil {
+		clearGuestCookie(c)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart token"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	carts := db.GetCartCollection()
+
+	// taking the guest cart out first means a repeated merge finds nothing
+	// instead of adding the same items twice
+	var guestCart cartmodel.Cart
+	err = carts.FindOneAndDelete(ctx, bson.M{"guestId": guestID}).Decode(&guestCart)
+	if err == mongo.ErrNoDocuments {
+		clearGuestCookie(c)
+		c.JSON(http.StatusOK, gin.H{"message": "No guest cart to merge", "conflicts": []dto.MergeConflict{}})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	userCart, conflicts, err := mergeIntoCart(ctx, owner, guestCart.Items, guestCart.SavedForLater, guestCart.CouponCode)
+	if err != nil {
+		restoreGuestCart(ctx, guestCart)
+		respondCartError(c, err)
+		return
+	}
+
+	clearGuestCookie(c)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Guest cart merged",
+		"cart":      userCart,
+		"conflicts": conflicts,
+	})
+}
+
+// mergeIntoCart adds items, and saved for later lines, to the owner's cart,
+// creating the cart if there is none. The cart is replaced only if it is
+// still at the version merged into, otherwise the merge is done again.
+// couponCode is kept only when the cart has no coupon of its own.
+func mergeIntoCart(ctx context.Context, owner cartOwner, items []cartmodel.Item, saved []cartmodel.Item, couponCode string) (cartmodel.Cart, []dto.MergeConflict, error) {
+	carts := db.GetCartCollection()
+	for attempt := 0; attempt < maxCartRetries; attempt++ {
+		var userCart cartmodel.Cart
+		err := carts.FindOne(ctx, owner.filter()).Decode(&userCart)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return userCart, nil, fmt.Errorf("%w: %v", errCartStore, err)
+		}
+		cartExists := err == nil
+		if !cartExists {
+			userCart = cartmodel.Cart{Items: []cartmodel.Item{}, CreatedAt: time.Now()}
+		}
+
+		conflicts, err := mergeItems(&userCart, items)
+		if err != nil {
+			return userCart, nil, err
+		}
+		userCart.SavedForLater = mergeSaved(userCart, saved)
+		if userCart.CouponCode == "" {
+			userCart.CouponCode = couponCode
+		}
+		owner.stamp(&userCart)
+		userCart.UpdatedAt = time.Now()
+
+		if cartExists {
+			filter := atVersion(owner.filter(), userCart.Version)
+			userCart.Version++
+			var res *mongo.UpdateResult
+			res, err = carts.ReplaceOne(ctx, filter, userCart)
+			if err == nil && res.MatchedCount == 0 {
+				continue
+			}
+		} else {
+			userCart.Version = 1
+			_, err = carts.InsertOne(ctx, userCart)
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+		}
+		if err != nil {
+			return userCart, nil, fmt.Errorf("%w: %v", errCartStore, err)
+		}
+		return userCart, conflicts, nil
+	}
+	return cartmodel.Cart{}, nil, errCartChanged
+}
+
+// mergeItems adds guest lines to cart and caps each line it touched by stock
+func mergeItems(cart *cartmodel.Cart, guestItems []cartmodel.Item) ([]dto.MergeConflict, error) {
+	conflicts := []dto.MergeConflict{}
+	if len(guestItems) == 0 {
+		return conflicts, nil
+	}
+
+	touched := make(map[string]bool, len(guestItems))
+	for _, guestItem := range guestItems {
+		key := guestItem.ProductID.Hex()
+		touched[key] = true
+		found := false
+		for i, item := range cart.Items {
+			if item.ProductID == guestItem.ProductID {
+				cart.Items[i].Quantity += guestItem.Quantity
+				found = true
+				break
+			}
+		}
+		if !found {
+			cart.Items = append(cart.Items, guestItem)
+		}
+	}
+
+	quote, err := quotePrices(cart.Items)
+	if err != nil {
+		return nil, err
+	}
+	lines := make(map[string]dto.QuoteLine, len(quote.Items))
+	for _, line := range quote.Items {
+		lines[line.ProductID] = line
+	}
+
+	merged := cart.Items[:0]
+	for _, item := range cart.Items {
+		key := item.ProductID.Hex()
+		if !touched[key] {
+			merged = append(merged, item)
+			continue
+		}
+		line, ok := lines[key]
+		if !ok {
+			conflicts = append(conflicts, dto.MergeConflict{
+				ProductID: key,
+				Title:     item.Title,
+				Requested: item.Quantity,
+				Reason:    "no longer sold",
+			})
+			continue
+		}
+		item.Title = line.Title
+		item.Price = line.UnitPrice
+		if item.AddedPrice.Currency == "" {
+			item.AddedPrice = line.UnitPrice
+		}
+		if line.Stock < item.Quantity {
+			available := line.Stock
+			if available < 0 {
+				available = 0
+			}
+			reason := fmt.Sprintf("only %d left in stock", available)
+			if available == 0 {
+				reason = "out of stock"
+			}
+			conflicts = append(conflicts, dto.MergeConflict{
+				ProductID: key,
+				Title:     line.Title,
+				Requested: item.Quantity,
+				Quantity:  available,
+				Reason:    reason,
+			})
+			if available == 0 {
+				continue
+			}
+			item.Quantity = available
+		}
+		merged = append(merged, item)
+	}
+	cart.Items = merged
+	return conflicts, nil
+}
+
+// mergeSaved adds saved lines for products the cart does not already hold,
+// either in its items or in its own saved for later list
+func mergeSaved(cart cartmodel.Cart, saved []cartmodel.Item) []cartmodel.Item {
+	merged := cart.SavedForLater
+	for _, item := range saved {
+		if findLine(cart.Items, item.ProductID) >= 0 || findLine(merged, item.ProductID) >= 0 {
+			continue
+		}
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// restoreGuestCart puts back a guest cart whose merge failed so nothing is lost
+func restoreGuestCart(ctx context.Context, guestCart cartmodel.Cart) {
+	if _, err := db.GetCartCollection().InsertOne(ctx, guestCart); err != nil {
+		log.Printf("❌ cartService failed to restore guest cart %s: %v", guestCart.GuestID, err)
+	}
+}
+
+func clearGuestCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(jwtutils.GuestCookieName, "", -1, "/api/cart", "", jwtutils.GuestCookieSecure(), true)
+}
@@ -0,0 +1,530 @@
+package cartcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/dto"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxNamedCarts is how many named carts one user may keep
+const maxNamedCarts = 20
+
+var errNamedCartNotFound = errors.New("named cart not found")
+
+func CreateNamedCart(c *gin.Context) {
+	var namedDTO dto.NamedCartDTO
+	if err := c.ShouldBindJSON(&namedDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(namedDTO.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := db.GetNamedCartCollection().CountDocuments(ctx, bson.M{"userId": owner.userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count >= maxNamedCarts {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can keep at most %d named carts", maxNamedCarts)})
+		return
+	}
+
+	namedCart := cartmodel.NamedCart{
+		UserID:    owner.userID,
+		Name:      name,
+		Items:     []cartmodel.Item{},
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	res, err := db.GetNamedCartCollection().InsertOne(ctx, namedCart)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A cart with that name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create cart"})
+		return
+	}
+	namedCart.ID = res.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Named cart created",
+		"namedCart": namedCart,
+	})
+}
+
+func GetNamedCarts(c *gin.Context) {
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := db.GetNamedCartCollection().Find(ctx, bson.M{"userId": owner.userID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	namedCarts := []cartmodel.NamedCart{}
+	if err := cursor.All(ctx, &namedCarts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse cart data"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"namedCarts": namedCarts})
+}
+
+// GetNamedCart returns one named cart with its lines priced and flagged like the active cart's
+func GetNamedCart(c *gin.Context) {
+	owner, namedCartID, ok := namedCartParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var namedCart cartmodel.NamedCart
+	err := db.GetNamedCartCollection().FindOne(ctx, bson.M{"_id": namedCartID, "userId": owner.userID}).Decode(&namedCart)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Named cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := priceItems(namedCart.Items); err != nil {
+		log.Printf("⚠️ cartService serving stored prices for named cart, quote failed: %v", err)
+	}
+	c.JSON(http.StatusOK, gin.H{"namedCart": namedCart})
+}
+
+func RenameNamedCart(c *gin.Context) {
+	var namedDTO dto.NamedCartDTO
+	if err := c.ShouldBindJSON(&namedDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, namedCartID, ok := namedCartParams(c)
+	if !ok {
+		return
+	}
+	name := strings.TrimSpace(namedDTO.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var namedCart cartmodel.NamedCart
+	err := db.GetNamedCartCollection().FindOneAndUpdate(ctx,
+		bson.M{"_id": namedCartID, "userId": owner.userID},
+		bson.M{
+			"$set": bson.M{"name": name, "updatedAt": time.Now()},
+			"$inc": bson.M{"version": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&namedCart)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A cart with that name already exists"})
+		return
+	}
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Named cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename cart"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Named cart renamed",
+		"namedCart": namedCart,
+	})
+}
+
+func DeleteNamedCart(c *gin.Context) {
+	owner, namedCartID, ok := namedCartParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := db.GetNamedCartCollection().DeleteOne(ctx, bson.M{"_id": namedCartID, "userId": owner.userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete cart"})
+		return
+	}
+	if res.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Named cart not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Named cart deleted"})
+}
+
+// AddNamedCartItem adds a product to a named cart. Nothing is reserved by
+// parking it, so the product only has to exist; stock is checked once the
+// line moves into the active cart.
+func AddNamedCartItem(c *gin.Context) {
+	var item dto.Item
+	if err := c.ShouldBindJSON(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, namedCartID, ok := namedCartParams(c)
+	if !ok {
+		return
+	}
+	productObjectID, err := primitive.ObjectIDFromHex(item.ProductID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+
+	quote, err := quotePrices([]cartmodel.Item{{ProductID: productObjectID, Quantity: item.Quantity}})
+	if err != nil {
+		respondQuoteError(c, err)
+		return
+	}
+	if len(quote.Items) == 0 {
+		respondQuoteError(c, errProductNotFound)
+		return
+	}
+	line := quote.Items[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	namedCart, err := changeNamedCart(ctx, owner, namedCartID, func(namedCart *cartmodel.NamedCart) error {
+		namedCart.Items = addLines(namedCart.Items, []cartmodel.Item{{
+			ProductID:  productObjectID,
+			Title:      line.Title,
+			Price:      line.UnitPrice,
+			AddedPrice: line.UnitPrice,
+			Quantity:   item.Quantity,
+		}})
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Item added to named cart",
+		"namedCart": namedCart,
+	})
+}
+
+func RemoveNamedCartItem(c *gin.Context) {
+	owner, namedCartID, ok := namedCartParams(c)
+	if !ok {
+		return
+	}
+	productObjectID, err := primitive.ObjectIDFromHex(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	namedCart, err := changeNamedCart(ctx, owner, namedCartID, func(namedCart *cartmodel.NamedCart) error {
+		i := findLine(namedCart.Items, productObjectID)
+		if i < 0 {
+			return errLineNotFound
+		}
+		namedCart.Items = append(namedCart.Items[:i], namedCart.Items[i+1:]...)
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Item removed from named cart",
+		"namedCart": namedCart,
+	})
+}
+
+// MoveNamedCartToCart moves one line, or all of them, from a named cart into
+// the active cart. Lines are merged like a guest cart at login: quantities
+// add up and are capped by stock. Whatever did not fit stays in the named
+// cart and is reported as a conflict.
+func MoveNamedCartToCart(c *gin.Context) {
+	var move dto.MoveLines
+	if err := c.ShouldBindJSON(&move); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, namedCartID, ok := namedCartParams(c)
+	if !ok {
+		return
+	}
+	selected, ok := selectedProduct(c, move.ProductID)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// the lines are taken out first so a repeated request cannot move them twice
+	var taken []cartmodel.Item
+	_, err := changeNamedCart(ctx, owner, namedCartID, func(namedCart *cartmodel.NamedCart) error {
+		taken, namedCart.Items = takeLines(namedCart.Items, selected)
+		if len(taken) == 0 {
+			return errLineNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	cart, conflicts, err := mergeIntoCart(ctx, owner, taken, nil, "")
+	if err != nil {
+		returnToNamedCart(ctx, owner, namedCartID, taken)
+		respondCartError(c, err)
+		return
+	}
+
+	// a line capped by stock leaves what did not fit in the named cart
+	var leftover []cartmodel.Item
+	for _, conflict := range conflicts {
+		for _, item := range taken {
+			if item.ProductID.Hex() != conflict.ProductID {
+				continue
+			}
+			remaining := conflict.Requested - conflict.Quantity
+			if remaining > item.Quantity {
+				remaining = item.Quantity
+			}
+			if remaining > 0 {
+				item.Quantity = remaining
+				leftover = append(leftover, item)
+			}
+		}
+	}
+	namedCart := returnToNamedCart(ctx, owner, namedCartID, leftover)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Items moved to cart",
+		"cart":      cart,
+		"namedCart": namedCart,
+		"conflicts": conflicts,
+	})
+}
+
+// MoveCartToNamedCart moves one line, or the whole active cart, into a named cart
+func MoveCartToNamedCart(c *gin.Context) {
+	var move dto.MoveLines
+	if err := c.ShouldBindJSON(&move); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, namedCartID, ok := namedCartParams(c)
+	if !ok {
+		return
+	}
+	selected, ok := selectedProduct(c, move.ProductID)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// make sure the named cart exists before emptying anything out of the active one
+	count, err := db.GetNamedCartCollection().CountDocuments(ctx, bson.M{"_id": namedCartID, "userId": owner.userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Named cart not found"})
+		return
+	}
+
+	var taken []cartmodel.Item
+	cart, err := changeCart(ctx, owner, nil, func(cart *cartmodel.Cart) error {
+		taken, cart.Items = takeLines(cart.Items, selected)
+		if len(taken) == 0 {
+			return errLineNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	namedCart, err := changeNamedCart(ctx, owner, namedCartID, func(namedCart *cartmodel.NamedCart) error {
+		namedCart.Items = addLines(namedCart.Items, taken)
+		return nil
+	})
+	if err != nil {
+		// the lines were in the cart a moment ago, so they go back without a stock check
+		if _, restoreErr := changeCart(ctx, owner, nil, func(cart *cartmodel.Cart) error {
+			cart.Items = addLines(cart.Items, taken)
+			return nil
+		}); restoreErr != nil {
+			log.Printf("❌ cartService failed to restore cart lines for user %s: %v", owner.userID.Hex(), restoreErr)
+		}
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Items moved to named cart",
+		"cart":      cart,
+		"namedCart": namedCart,
+	})
+}
+
+// changeNamedCart is changeCart for a named cart; a lost race is retried
+func changeNamedCart(ctx context.Context, owner cartOwner, namedCartID primitive.ObjectID, change func(namedCart *cartmodel.NamedCart) error) (cartmodel.NamedCart, error) {
+	namedCarts := db.GetNamedCartCollection()
+	filter := bson.M{"_id": namedCartID, "userId": owner.userID}
+	for attempt := 0; attempt < maxCartRetries; attempt++ {
+		var namedCart cartmodel.NamedCart
+		err := namedCarts.FindOne(ctx, filter).Decode(&namedCart)
+		if err == mongo.ErrNoDocuments {
+			return namedCart, errNamedCartNotFound
+		}
+		if err != nil {
+			return namedCart, fmt.Errorf("%w: %v", errCartStore, err)
+		}
+		if err := change(&namedCart); err != nil {
+			return namedCart, err
+		}
+		if namedCart.Items == nil {
+			namedCart.Items = []cartmodel.Item{}
+		}
+
+		var committed cartmodel.NamedCart
+		err = namedCarts.FindOneAndUpdate(ctx,
+			bson.M{"_id": namedCartID, "userId": owner.userID, "version": namedCart.Version},
+			bson.M{
+				"$set": bson.M{"items": namedCart.Items, "updatedAt": time.Now()},
+				"$inc": bson.M{"version": 1},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&committed)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return namedCart, fmt.Errorf("%w: %v", errCartStore, err)
+		}
+		return committed, nil
+	}
+	return cartmodel.NamedCart{}, errCartChanged
+}
+
+// returnToNamedCart adds lines back to a named cart after a move that did
+// not take them all, and returns the named cart as it is afterwards
+func returnToNamedCart(ctx context.Context, owner cartOwner, namedCartID primitive.ObjectID, lines []cartmodel.Item) *cartmodel.NamedCart {
+	namedCart, err := changeNamedCart(ctx, owner, namedCartID, func(namedCart *cartmodel.NamedCart) error {
+		namedCart.Items = addLines(namedCart.Items, lines)
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ cartService failed to return lines to named cart %s: %v", namedCartID.Hex(), err)
+		return nil
+	}
+	return &namedCart
+}
+
+// takeLines splits items into the line for productID, or every line when
+// productID is nil, and the lines that stay behind
+func takeLines(items []cartmodel.Item, productID *primitive.ObjectID) (taken, kept []cartmodel.Item) {
+	kept = []cartmodel.Item{}
+	for _, item := range items {
+		if productID == nil || item.ProductID == *productID {
+			taken = append(taken, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	return taken, kept
+}
+
+// addLines adds lines to items, summing the quantities of products already there
+func addLines(items []cartmodel.Item, lines []cartmodel.Item) []cartmodel.Item {
+	for _, line := range lines {
+		if i := findLine(items, line.ProductID); i >= 0 {
+			items[i].Quantity += line.Quantity
+			continue
+		}
+		items = append(items, line)
+	}
+	return items
+}
+
+// namedCartParams reads the signed-in owner and the :id of the named cart,
+// answering the request itself when either is unusable
+func namedCartParams(c *gin.Context) (cartOwner, primitive.ObjectID, bool) {
+	owner, err := ownerFromContext(c)
+	if err != nil || owner.isGuest() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return cartOwner{}, primitive.NilObjectID, false
+	}
+	namedCartID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart ID format"})
+		return cartOwner{}, primitive.NilObjectID, false
+	}
+	return owner, namedCartID, true
+}
+
+// selectedProduct parses an optional product ID; nil means every line
+func selectedProduct(c *gin.Context, productID string) (*primitive.ObjectID, bool) {
+	if productID == "" {
+		return nil, true
+	}
+	productObjectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return nil, false
+	}
+	return &productObjectID, true
+}
@@ -0,0 +1,82 @@
+package cartcontroller
+
+import (
+	"errors"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/jwtutils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cartOwner is whoever a cart request acts for: a signed-in user or a guest
+type cartOwner struct {
+	userID  primitive.ObjectID
+	email   string
+	guestID string
+}
+
+// ownerFromContext reads the owner set by CreateCartOwnerMiddleware or CreateAuthMiddleware
+func ownerFromContext(c *gin.Context) (cartOwner, error) {
+	if userID := c.GetString("UserID"); userID != "" {
+		userObjectID, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return cartOwner{}, errors.New("Invalid user ID format")
+		}
+		return cartOwner{userID: userObjectID, email: c.GetString("Email")}, nil
+	}
+	if guestID := c.GetString("GuestID"); guestID != "" {
+		return cartOwner{guestID: guestID}, nil
+	}
+	return cartOwner{}, errors.New("Cart owner not found in context")
+}
+
+func (o cartOwner) isGuest() bool {
+	return o.guestID != ""
+}
+
+// filter matches the owner's cart
+func (o cartOwner) filter() bson.M {
+	if o.isGuest() {
+		return bson.M{"guestId": o.guestID}
+	}
+	return bson.M{"userId": o.userID}
+}
+
+// stamp marks cart as the owner's; guest carts get their expiry pushed back
+// on every write so only abandoned ones are removed
+func (o cartOwner) stamp(cart *cartmodel.Cart) {
+	if o.isGuest() {
+		cart.GuestID = o.guestID
+		expiresAt := time.Now().Add(jwtutils.GuestCartTTL())
+		cart.ExpiresAt = &expiresAt
+		return
+	}
+	cart.UserID = o.userID
+	cart.Email = o.email
+}
+
+// touch adds the bookkeeping fields every cart write sets to a $set document
+func (o cartOwner) touch(set bson.M) bson.M {
+	now := time.Now()
+	set["updatedAt"] = now
+	if o.isGuest() {
+		set["expiresAt"] = now.Add(jwtutils.GuestCartTTL())
+	} else if o.email != "" {
+		set["email"] = o.email
+	}
+	return set
+}
+
+// atVersion narrows filter to a cart still at version; carts saved before
+// versioning have no version field and count as version 0
+func atVersion(filter bson.M, version int64) bson.M {
+	if version == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	} else {
+		filter["version"] = version
+	}
+	return filter
+}
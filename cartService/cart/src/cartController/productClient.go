@@ -0,0 +1,136 @@
+package cartcontroller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/dto"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var productClient = &http.Client{Timeout: 10 * time.Second}
+
+var errProductNotFound = errors.New("product not found")
+
+// insufficientStockError reports how many units the product service can supply
+type insufficientStockError struct {
+	Available int
+}
+
+func (e *insufficientStockError) Error() string {
+	return fmt.Sprintf("only %d left in stock", e.Available)
+}
+
+// quotePrices asks the product service for the price of each cart line right now
+func quotePrices(items []cartmodel.Item) (dto.QuoteResponse, error) {
+	var quote dto.QuoteResponse
+
+	quoteReq := dto.QuoteRequest{Items: make([]dto.QuoteItem, 0, len(items))}
+	for _, item := range items {
+		quoteReq.Items = append(quoteReq.Items, dto.QuoteItem{
+			ProductID: item.ProductID.Hex(),
+			Quantity:  item.Quantity,
+		})
+	}
+	body, err := json.Marshal(quoteReq)
+	if err != nil {
+		return quote, fmt.Errorf("failed to encode quote request")
+	}
+
+	resp, err := productClient.Post(os.Getenv("PRODUCT_SERVICE_URL")+"/api/product/quote", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return quote, fmt.Errorf("failed to connect to product service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return quote, fmt.Errorf("product service failed with status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return quote, fmt.Errorf("failed to decode quote")
+	}
+	return quote, nil
+}
+
+// quoteProduct prices a single product for quantity units and checks there is
+// enough stock, so nothing enters the cart at a price the client made up
+func quoteProduct(productID primitive.ObjectID, quantity int) (dto.QuoteLine, error) {
+	quote, err := quotePrices([]cartmodel.Item{{ProductID: productID, Quantity: quantity}})
+	if err != nil {
+		return dto.QuoteLine{}, err
+	}
+	if len(quote.Items) == 0 {
+		return dto.QuoteLine{}, errProductNotFound
+	}
+	line := quote.Items[0]
+	if line.Stock < quantity {
+		return line, &insufficientStockError{Available: line.Stock}
+	}
+	return line, nil
+}
+
+// applyCurrentPrices replaces each line's stored price with the one valid now,
+// keeping the list price alongside when a sale is running. Lines whose price
+// moved since they were added, or that can no longer be bought, are flagged.
+func applyCurrentPrices(cart *cartmodel.Cart) error {
+	return priceItems(cart.Items)
+}
+
+// priceItems is applyCurrentPrices for any list of lines, such as the saved
+// for later list or a named cart; items is updated in place
+func priceItems(items []cartmodel.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+	quote, err := quotePrices(items)
+	if err != nil {
+		return err
+	}
+	applyQuote(items, quote)
+	return nil
+}
+
+// applyQuote copies a quote onto items and flags the lines it affects
+func applyQuote(items []cartmodel.Item, quote dto.QuoteResponse) {
+	lines := make(map[string]dto.QuoteLine, len(quote.Items))
+	for _, line := range quote.Items {
+		lines[line.ProductID] = line
+	}
+	for i, item := range items {
+		line, ok := lines[item.ProductID.Hex()]
+		if !ok {
+			items[i].Unavailable = true
+			items[i].UnavailableReason = "no longer sold"
+			continue
+		}
+		items[i].Title = line.Title
+		items[i].Price = line.UnitPrice
+		items[i].SellerID = line.SellerID
+		items[i].Category = line.Category
+		if line.SaleID != "" {
+			original := line.OriginalPrice
+			items[i].OriginalPrice = &original
+			items[i].SaleID = line.SaleID
+		}
+		// lines added before prices were tracked have no baseline to compare against
+		if item.AddedPrice.Currency != "" && item.AddedPrice != line.UnitPrice {
+			previous := item.AddedPrice
+			items[i].PriceChanged = true
+			items[i].PreviousPrice = &previous
+		}
+		switch {
+		case line.Stock <= 0:
+			items[i].Unavailable = true
+			items[i].UnavailableReason = "out of stock"
+		case line.Stock < item.Quantity:
+			items[i].Unavailable = true
+			items[i].UnavailableReason = fmt.Sprintf("only %d left in stock", line.Stock)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cartcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/dto"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HandleProductEvent keeps cart lines in step with the product service:
+// price changes are copied onto every cart holding the product and deleted
+// products are removed. Lines already at a newer version are left alone.
+func HandleProductEvent(body []byte) {
+	var event dto.ProductEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("❌ cartService invalid product event: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	carts := db.GetCartCollection()
+	switch event.Type {
+	case "ProductUpdated", "ProductStockChanged":
+		if event.Product == nil {
+			return
+		}
+		olderVersion := bson.A{
+			bson.M{"productVersion": bson.M{"$exists": false}},
+			bson.M{"productVersion": bson.M{"$lt": event.Version}},
+		}
+		// only carts with a stale line match, so the version only moves when
+		// a price is actually rewritten and concurrent cart writes notice it
+		_, err := carts.UpdateMany(ctx,
+			bson.M{"items": bson.M{"$elemMatch": bson.M{"productId": event.ProductID, "$or": olderVersion}}},
+			bson.M{
+				"$set": bson.M{
+					"items.$[line].price":          event.Product.Price,
+					"items.$[line].productVersion": event.Version,
+				},
+				"$inc": bson.M{"version": 1},
+			},
+			options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+				bson.M{
+					"line.productId": event.ProductID,
+					"$or": bson.A{
+						bson.M{"line.productVersion": bson.M{"$exists": false}},
+						bson.M{"line.productVersion": bson.M{"$lt": event.Version}},
+					},
+				},
+			}}),
+		)
+		if err != nil {
+			log.Printf("❌ cartService failed to reprice product %s: %v", event.ProductID.Hex(), err)
+		}
+	case "ProductDeleted":
+		_, err := carts.UpdateMany(ctx,
+			bson.M{"items.productId": event.ProductID},
+			bson.M{
+				"$pull": bson.M{"items": bson.M{"productId": event.ProductID}},
+				"$set":  bson.M{"updatedAt": time.Now()},
+				"$inc":  bson.M{"version": 1},
+			},
+		)
+		if err != nil {
+			log.Printf("❌ cartService failed to remove product %s: %v", event.ProductID.Hex(), err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package cartcontroller
+
+import (
+	"context"
+	"net/http"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/dto"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SaveForLater moves a line from the active cart to the saved for later
+// list; a product already saved has the quantities added together
+func SaveForLater(c *gin.Context) {
+	var move dto.MoveItem
+	if err := c.ShouldBindJSON(&move); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	productObjectID, err := primitive.ObjectIDFromHex(move.ProductID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	committed, err := changeCart(ctx, owner, move.Version, func(cart *cartmodel.Cart) error {
+		i := findLine(cart.Items, productObjectID)
+		if i < 0 {
+			return errLineNotFound
+		}
+		line := cart.Items[i]
+		cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+		if j := findLine(cart.SavedForLater, productObjectID); j >= 0 {
+			cart.SavedForLater[j].Quantity += line.Quantity
+		} else {
+			cart.SavedForLater = append(cart.SavedForLater, line)
+		}
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item saved for later",
+		"cart":    committed,
+	})
+}
+
+// MoveSavedToCart moves a saved line back into the active cart at the
+// current price, as long as there is stock for the combined quantity
+func MoveSavedToCart(c *gin.Context) {
+	var move dto.MoveItem
+	if err := c.ShouldBindJSON(&move); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	productObjectID, err := primitive.ObjectIDFromHex(move.ProductID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	committed, err := changeCart(ctx, owner, move.Version, func(cart *cartmodel.Cart) error {
+		j := findLine(cart.SavedForLater, productObjectID)
+		if j < 0 {
+			return errLineNotFound
+		}
+		saved := cart.SavedForLater[j]
+
+		quantity := saved.Quantity
+		i := findLine(cart.Items, productObjectID)
+		if i >= 0 {
+			quantity += cart.Items[i].Quantity
+		}
+		line, err := quoteProduct(productObjectID, quantity)
+		if err != nil {
+			return err
+		}
+
+		cart.SavedForLater = append(cart.SavedForLater[:j], cart.SavedForLater[j+1:]...)
+		if i >= 0 {
+			cart.Items[i].Quantity = quantity
+			cart.Items[i].Title = line.Title
+			cart.Items[i].Price = line.UnitPrice
+			return nil
+		}
+		saved.Title = line.Title
+		saved.Price = line.UnitPrice
+		// the price it was saved at stays the baseline, so a change while it
+		// was parked is flagged once it is back in the cart
+		if saved.AddedPrice.Currency == "" {
+			saved.AddedPrice = line.UnitPrice
+		}
+		cart.Items = append(cart.Items, saved)
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item moved to cart",
+		"cart":    committed,
+	})
+}
+
+func RemoveSavedItem(c *gin.Context) {
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	productObjectID, err := primitive.ObjectIDFromHex(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	committed, err := changeCart(ctx, owner, nil, func(cart *cartmodel.Cart) error {
+		j := findLine(cart.SavedForLater, productObjectID)
+		if j < 0 {
+			return errLineNotFound
+		}
+		cart.SavedForLater = append(cart.SavedForLater[:j], cart.SavedForLater[j+1:]...)
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Saved item removed",
+		"cart":    committed,
+	})
+}
@@ -0,0 +1,105 @@
+package cartcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/dto"
+	"supernova/shared/money"
+	"supernova/shared/pricing"
+)
+
+// cartSummary prices a cart the way checkout will: lines that can still be
+// bought, the coupon's line discounts when it is valid, and tax and shipping
+// for the shopper's default address. Guests get a home-country estimate.
+func cartSummary(cart cartmodel.Cart, discount *dto.CouponDiscount, token string) (pricing.Summary, error) {
+	rates, err := fetchExchangeRates()
+	if err != nil {
+		return pricing.Summary{}, err
+	}
+	var destination *pricing.Destination
+	if token != "" {
+		addresses, err := fetchAddresses(token)
+		if err != nil {
+			return pricing.Summary{}, err
+		}
+		if len(addresses) > 0 {
+			destination = &pricing.Destination{Country: addresses[0].Country, State: addresses[0].State}
+		}
+	}
+	return summarize(cart, discount, rates, destination)
+}
+
+// summarize is cartSummary once the rates and destination are known
+func summarize(cart cartmodel.Cart, discount *dto.CouponDiscount, rates dto.ExchangeRates, destination *pricing.Destination) (pricing.Summary, error) {
+	lineDiscounts := map[string]money.Money{}
+	if discount != nil && discount.Valid {
+		for _, line := range discount.Lines {
+			lineDiscounts[line.ProductID] = line.Discount
+		}
+	}
+
+	lines := make([]pricing.Line, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if item.Unavailable {
+			continue
+		}
+		productID := item.ProductID.Hex()
+		lines = append(lines, pricing.Line{
+			ProductID: productID,
+			UnitPrice: item.Price,
+			Quantity:  item.Quantity,
+			Discount:  lineDiscounts[productID],
+		})
+	}
+
+	currency := pricing.SettlementCurrency()
+	return pricing.Calculate(lines, currency, func(from money.Currency) (float64, error) {
+		return pricing.ExchangeRate(rates.Rates, from, currency)
+	}, destination)
+}
+
+// fetchExchangeRates reads the current rate table from the product service
+func fetchExchangeRates() (dto.ExchangeRates, error) {
+	var rates dto.ExchangeRates
+
+	resp, err := productClient.Get(os.Getenv("PRODUCT_SERVICE_URL") + "/api/product/rates")
+	if err != nil {
+		return rates, fmt.Errorf("failed to connect to product service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rates, fmt.Errorf("product service failed with status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rates); err != nil {
+		return rates, fmt.Errorf("failed to decode exchange rates")
+	}
+	return rates, nil
+}
+
+// fetchAddresses returns the user's address book; the first address is their default
+func fetchAddresses(token string) ([]dto.Address, error) {
+	req, err := http.NewRequest("GET", os.Getenv("AUTH_SERVICE_URL")+"/api/auth/user", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth request")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := productClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to auth service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth service failed with status: %d", resp.StatusCode)
+	}
+	var authResp dto.AuthUserResponse
+	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
+		return nil, fmt.Errorf("failed to decode user details")
+	}
+	return authResp.UserInfo.Addresses, nil
+}
@@ -0,0 +1,253 @@
+package cartcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	cartmodel "supernova/cartService/cart/src/cartModel"
+	"supernova/cartService/cart/src/db"
+	"supernova/cartService/cart/src/dto"
+	"supernova/shared/pricing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ValidateCart checks the cart the way CreateOrder will, without placing an
+// order: every line against productService for availability and price, the
+// currencies against the rate table, the coupon, and the shipping and billing
+// addresses against authService. The optional body takes the same address
+// choices as CreateOrder. Problems come back as a structured list instead of
+// the first error checkout would stop at.
+func ValidateCart(c *gin.Context) {
+	owner, err := ownerFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var request dto.ValidateCartRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid validation request", "details": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var existingCart cartmodel.Cart
+	err = db.GetCartCollection().FindOne(ctx, owner.filter()).Decode(&existingCart)
+	if err != nil && err != mongo.ErrNoDocuments {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := dto.CartValidation{
+		Lines:  []dto.LineValidation{},
+		Issues: []dto.ValidationIssue{},
+	}
+	if len(existingCart.Items) == 0 {
+		result.Issues = append(result.Issues, blocking("empty_cart", "Your cart is empty"))
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	// without a quote or a rate table nothing can be said about the lines, so
+	// that is reported as an error rather than as an invalid cart
+	quote, err := quotePrices(existingCart.Items)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	applyQuote(existingCart.Items, quote)
+	rates, err := fetchExchangeRates()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	stock := make(map[string]int, len(quote.Items))
+	for _, line := range quote.Items {
+		stock[line.ProductID] = line.Stock
+	}
+	currency := pricing.SettlementCurrency()
+	ratesMissing := false
+	for _, item := range existingCart.Items {
+		productID := item.ProductID.Hex()
+		line := dto.LineValidation{
+			ProductID: productID,
+			Title:     item.Title,
+			Quantity:  item.Quantity,
+			Issues:    []dto.ValidationIssue{},
+		}
+
+		available, sold := stock[productID]
+		switch {
+		case !sold:
+			line.Issues = append(line.Issues, blocking("not_sold", "This product is no longer sold"))
+		case available <= 0:
+			issue := blocking("out_of_stock", "This product is out of stock")
+			issue.Available = &available
+			line.Issues = append(line.Issues, issue)
+		case available < item.Quantity:
+			issue := blocking("insufficient_stock", fmt.Sprintf("Only %d left in stock", available))
+			issue.Available = &available
+			line.Issues = append(line.Issues, issue)
+		}
+
+		if sold {
+			if item.PriceChanged {
+				current := item.Price
+				issue := warning("price_changed", fmt.Sprintf("The price changed from %s to %s since you added it", item.PreviousPrice, item.Price))
+				issue.PreviousPrice = item.PreviousPrice
+				issue.CurrentPrice = &current
+				line.Issues = append(line.Issues, issue)
+			}
+			if item.Price.Currency != currency {
+				if rate, err := pricing.ExchangeRate(rates.Rates, item.Price.Currency, currency); err != nil {
+					ratesMissing = true
+					line.Issues = append(line.Issues, blocking("no_exchange_rate",
+						fmt.Sprintf("Prices in %s cannot be charged in %s right now", item.Price.Currency, currency)))
+				} else {
+					line.Issues = append(line.Issues, warning("currency_converted",
+						fmt.Sprintf("Priced in %s and charged in %s at %.4f", item.Price.Currency, currency, rate)))
+				}
+			}
+		}
+		result.Lines = append(result.Lines, line)
+	}
+
+	discount := cartCouponDiscount(ctx, owner, existingCart)
+	if discount != nil && !discount.Valid {
+		result.Issues = append(result.Issues, warning("coupon_invalid",
+			fmt.Sprintf("Coupon %s will not be applied: %s", discount.Code, discount.Reason)))
+	}
+
+	destination, addressIssues := validateAddresses(owner, c.GetString("Token"), request)
+	result.Issues = append(result.Issues, addressIssues...)
+
+	result.Valid = true
+	for _, line := range result.Lines {
+		if hasBlocking(line.Issues) {
+			result.Valid = false
+		}
+	}
+	if hasBlocking(result.Issues) {
+		result.Valid = false
+	}
+
+	if !ratesMissing {
+		if summary, err := summarize(existingCart, discount, rates, destination); err != nil {
+			log.Printf("⚠️ cartService validated cart without totals: %v", err)
+		} else {
+			result.Summary = &summary
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// validateAddresses resolves the shipping and billing addresses from the
+// same choices CreateOrder gets, by the same rules: an address book entry by
+// ID, an inline address, or by default the first entry for shipping and the
+// shipping address for billing
+func validateAddresses(owner cartOwner, token string, request dto.ValidateCartRequest) (*pricing.Destination, []dto.ValidationIssue) {
+	if owner.isGuest() || token == "" {
+		return nil, []dto.ValidationIssue{blocking("sign_in_required", "Sign in to choose a shipping address")}
+	}
+	addresses, err := fetchAddresses(token)
+	if err != nil {
+		return nil, []dto.ValidationIssue{blocking("address_unavailable", "Your address book could not be checked, please retry")}
+	}
+
+	var issues []dto.ValidationIssue
+	shipping, issue := resolveAddress(addresses, request.ShippingAddress, nil, "shipping")
+	if issue != nil {
+		issues = append(issues, *issue)
+	}
+	if shipping == nil {
+		return nil, issues
+	}
+	if _, issue := resolveAddress(addresses, request.BillingAddress, shipping, "billing"); issue != nil {
+		issues = append(issues, *issue)
+	}
+	return &pricing.Destination{Country: shipping.Country, State: shipping.State}, issues
+}
+
+// resolveAddress is the order service's resolveAddress reporting an issue
+// instead of failing. The address is returned whenever one was picked, even
+// if incomplete, so totals can still be estimated.
+func resolveAddress(book []dto.Address, choice *dto.AddressChoice, fallback *dto.Address, kind string) (*dto.Address, *dto.ValidationIssue) {
+	if choice == nil {
+		if fallback != nil {
+			return fallback, nil
+		}
+		if len(book) == 0 {
+			issue := blocking("no_address", fmt.Sprintf("Add a %s address to check out", kind))
+			return nil, &issue
+		}
+		return checkAddress(book[0], kind)
+	}
+
+	if choice.AddressID != "" && choice.Address != nil {
+		issue := blocking("address_choice_invalid", fmt.Sprintf("Give either an addressId or an address for the %s address, not both", kind))
+		return nil, &issue
+	}
+	if choice.AddressID != "" {
+		for _, entry := range book {
+			if entry.ID == choice.AddressID {
+				return checkAddress(entry, kind)
+			}
+		}
+		issue := blocking("address_not_found", fmt.Sprintf("The %s address %s is not in your address book", kind, choice.AddressID))
+		return nil, &issue
+	}
+	if choice.Address != nil {
+		address := *choice.Address
+		address.ID = ""
+		return checkAddress(address, kind)
+	}
+	issue := blocking("address_choice_invalid", fmt.Sprintf("Give an addressId or an address for the %s address", kind))
+	return nil, &issue
+}
+
+// checkAddress reports an address missing a field shipping or tax needs
+func checkAddress(address dto.Address, kind string) (*dto.Address, *dto.ValidationIssue) {
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"street", address.Street},
+		{"city", address.City},
+		{"state", address.State},
+		{"country", address.Country},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		issue := blocking("address_incomplete", "Your "+kind+" address is missing its "+strings.Join(missing, ", "))
+		return &address, &issue
+	}
+	return &address, nil
+}
+
+func blocking(code, message string) dto.ValidationIssue {
+	return dto.ValidationIssue{Severity: dto.SeverityBlocking, Code: code, Message: message}
+}
+
+func warning(code, message string) dto.ValidationIssue {
+	return dto.ValidationIssue{Severity: dto.SeverityWarning, Code: code, Message: message}
+}
+
+func hasBlocking(issues []dto.ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == dto.SeverityBlocking {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,7 @@
 package cartmiddleware
 
 import (
-	"log"
 	"net/http"
-	"reflect"
 	"strings"
 	"supernova/cartService/cart/src/jwtutils"
 	"time"
@@ -12,6 +10,11 @@ import (
 )
 
 func CreateAuthMiddleware() gin.HandlerFunc {
+	return CreateRoleAuthMiddleware("user")
+}
+
+// CreateRoleAuthMiddleware verifies the token and only lets the given roles through
+func CreateRoleAuthMiddleware(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		
 		authHeader := c.GetHeader("Authorization")
@@ -43,11 +46,17 @@ func CreateAuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Role check
-		if claims.Role != "user" {
+		allowed := false
+		for _, role := range roles {
+			if claims.Role == role {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
 			return
 		}
-		log.Print(reflect.TypeOf(claims.UserID))
 
 		// Optional: check if blacklisted
 		// if isBlacklisted(token) { ... }
@@ -0,0 +1,42 @@
+package cartmiddleware
+
+import (
+	"net/http"
+	"supernova/cartService/cart/src/jwtutils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateCartOwnerMiddleware lets signed-in users and guests use the cart.
+// A bearer token is checked exactly like CreateAuthMiddleware; without one
+// the caller is identified by the signed cart_token cookie, which is issued
+// on the first visit and refreshed on every request.
+func CreateCartOwnerMiddleware() gin.HandlerFunc {
+	authenticate := CreateAuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			authenticate(c)
+			return
+		}
+
+		var guestID, token string
+		if cookie, err := c.Cookie(jwtutils.GuestCookieName); err == nil {
+			if id, err := jwtutils.ParseGuestToken(cookie); err == nil {
+				guestID, token = id, cookie
+			}
+		}
+		if guestID == "" {
+			var err error
+			guestID, token, err = jwtutils.NewGuestToken()
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guest cart"})
+				return
+			}
+		}
+
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(jwtutils.GuestCookieName, token, int(jwtutils.GuestCartTTL().Seconds()), "/api/cart", "", jwtutils.GuestCookieSecure(), true)
+		c.Set("GuestID", guestID)
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package cartmiddleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceTokenHeader carries the secret other services share with this one
+const ServiceTokenHeader = "X-Service-Token"
+
+// CreateServiceAuthMiddleware only lets through calls from other services,
+// which send SERVICE_TOKEN in X-Service-Token. Nothing passes while
+// SERVICE_TOKEN is unset.
+func CreateServiceAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := os.Getenv("SERVICE_TOKEN")
+		given := c.GetHeader(ServiceTokenHeader)
+		if expected == "" || subtle.ConstantTimeCompare([]byte(given), []byte(expected)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "only internal services may call this endpoint"})
+			return
+		}
+		c.Next()
+	}
+}
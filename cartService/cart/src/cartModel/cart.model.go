@@ -1,6 +1,7 @@
 package cartmodel
 
 import (
+	"supernova/shared/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,23 +9,46 @@ import (
 
 type Item struct {
 	ProductID 	primitive.ObjectID `bson:"productId" json:"productId"`
-	Price 		Price				`bson:"price" json:"price"`
+	Title 		string 			   `bson:"title,omitempty" json:"title,omitempty"`
+	Price 		money.Money			`bson:"price" json:"price"`
 	Quantity  	int                `bson:"quantity" json:"quantity" binding:"required,min=1"`
+	// AddedPrice is the price quoted when the item was added, kept to spot price changes
+	AddedPrice 	money.Money 	   `bson:"addedPrice" json:"-"`
+	// ProductVersion is the product version Price was taken from
+	ProductVersion int64 		   `bson:"productVersion,omitempty" json:"productVersion,omitempty"`
+	// OriginalPrice and SaleID are filled from a live quote when a sale applies; never stored
+	OriginalPrice  *money.Money    `bson:"-" json:"originalPrice,omitempty"`
+	SaleID         string          `bson:"-" json:"saleId,omitempty"`
+	// PriceChanged, PreviousPrice and Unavailable describe the live quote; never stored
+	PriceChanged   bool            `bson:"-" json:"priceChanged"`
+	PreviousPrice  *money.Money    `bson:"-" json:"previousPrice,omitempty"`
+	Unavailable    bool            `bson:"-" json:"unavailable"`
+	UnavailableReason string       `bson:"-" json:"unavailableReason,omitempty"`
+	// SellerID and Category come from the live quote and scope coupons; never stored
+	SellerID       string          `bson:"-" json:"-"`
+	Category       string          `bson:"-" json:"-"`
 }
-type Price struct {
-	Amount  	float64 `bson:"amount,omitempty" json:"amount,omitempty"`
-	Currency	Currency  `bson:"currency,omitempty" json:"currency,omitempty"`
-}
-type Currency string
-
-const (
-	USD Currency = "USD"
-	INR Currency = "INR"
-)
 
 type Cart struct {
-	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID     primitive.ObjectID `bson:"userId,omitempty" json:"userId,omitempty"`
+	// GuestID owns the cart of a visitor who has not signed in
+	GuestID    string             `bson:"guestId,omitempty" json:"guestId,omitempty"`
+	// Version goes up by one on every change to the cart
+	Version    int64              `bson:"version" json:"version"`
 	Items      []Item             `bson:"items" json:"items"`
+	// SavedForLater holds lines parked by the shopper; they are not checked
+	// out and survive the cart being cleared
+	SavedForLater []Item          `bson:"savedForLater,omitempty" json:"savedForLater"`
+	// CouponCode is the coupon the shopper applied; it is re-checked on every read
+	CouponCode string             `bson:"couponCode,omitempty" json:"couponCode,omitempty"`
 	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
+	// Email is where abandoned-cart reminders go; guests have none
+	Email      string             `bson:"email,omitempty" json:"-"`
+	// ReminderSentAt is when the last abandoned-cart reminder went out; one
+	// is sent per idle stretch, so a cart touched since can be reminded again
+	ReminderSentAt *time.Time     `bson:"reminderSentAt,omitempty" json:"-"`
+	// ExpiresAt is only set on guest carts; a TTL index removes them after it
+	ExpiresAt  *time.Time         `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
 }
@@ -0,0 +1,51 @@
+package cartmodel
+
+import (
+	"supernova/shared/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CouponType string
+
+const (
+	CouponPercentage CouponType = "percentage"
+	CouponFixed      CouponType = "fixed"
+)
+
+// Coupon is a discount code. It only applies to cart lines priced in its
+// currency, and when SellerID or Category is set, only to that seller's
+// products or that category.
+type Coupon struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	Code     string             `bson:"code" json:"code"`
+	Type     CouponType         `bson:"type" json:"type"`
+	Currency money.Currency     `bson:"currency" json:"currency"`
+	// Percent is used by percentage coupons, Amount by fixed ones
+	Percent float64     `bson:"percent,omitempty" json:"percent,omitempty"`
+	Amount  money.Money `bson:"amount,omitempty" json:"amount,omitempty"`
+	// MaxDiscount caps a percentage coupon; zero means no cap
+	MaxDiscount money.Money `bson:"maxDiscount,omitempty" json:"maxDiscount,omitempty"`
+	MinSpend    money.Money `bson:"minSpend,omitempty" json:"minSpend,omitempty"`
+	// UsageLimit and PerUserLimit are redemption counts; zero means unlimited
+	UsageLimit   int        `bson:"usageLimit" json:"usageLimit"`
+	PerUserLimit int        `bson:"perUserLimit" json:"perUserLimit"`
+	UsedCount    int        `bson:"usedCount" json:"usedCount"`
+	SellerID     string     `bson:"sellerId,omitempty" json:"sellerId,omitempty"`
+	Category     string     `bson:"category,omitempty" json:"category,omitempty"`
+	StartsAt     time.Time  `bson:"startsAt" json:"startsAt"`
+	ExpiresAt    *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	Active       bool       `bson:"active" json:"active"`
+	CreatedBy    string     `bson:"createdBy" json:"createdBy"`
+	CreatedAt    time.Time  `bson:"createdAt" json:"createdAt"`
+}
+
+// CouponUsage counts one user's redemptions of a coupon and the orders they were for
+type CouponUsage struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CouponID primitive.ObjectID `bson:"couponId" json:"couponId"`
+	UserID   primitive.ObjectID `bson:"userId" json:"userId"`
+	Count    int                `bson:"count" json:"count"`
+	OrderIDs []string           `bson:"orderIds" json:"orderIds"`
+}
@@ -0,0 +1,21 @@
+package cartmodel
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NamedCart is a list a signed-in user keeps next to their active cart, for
+// example one per project or cost centre. It is never checked out itself;
+// its items are moved into the active cart first.
+type NamedCart struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID primitive.ObjectID `bson:"userId" json:"userId"`
+	Name   string             `bson:"name" json:"name"`
+	Items  []Item             `bson:"items" json:"items"`
+	// Version goes up by one on every change, like Cart.Version
+	Version   int64     `bson:"version" json:"version"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
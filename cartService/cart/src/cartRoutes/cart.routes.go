@@ -11,11 +11,41 @@ func SetupCartRoutes(router *gin.Engine) {
 
 	r := router.Group("/api/cart")
 
-	securedRoutes := r.Use(cartmiddleware.CreateAuthMiddleware())
-
-	securedRoutes.POST("/item" , cartcontroller.AddItemToCart )
-	securedRoutes.PATCH("/updateitem" , cartcontroller.UpdateItemQuantity)
-	securedRoutes.PATCH("removeitem" , cartcontroller.RemoveItemFromCart)
-	securedRoutes.GET("/get" , cartcontroller.GetCart)
-	securedRoutes.DELETE("/clear" , cartcontroller.ClearCart)
-}
\ No newline at end of file
+	// merging needs a signed-in user; the guest cart comes from the cart_token cookie
+	r.POST("/merge" , cartmiddleware.CreateAuthMiddleware() , cartcontroller.MergeGuestCart)
+	// only the order service redeems, forwarding the shopper's token while an
+	// order is placed; a shopper calling directly could use up a coupon's limits
+	r.POST("/coupon/redeem" , cartmiddleware.CreateServiceAuthMiddleware() , cartmiddleware.CreateAuthMiddleware() , cartcontroller.RedeemCoupon)
+
+	couponRoutes := r.Group("/coupons" , cartmiddleware.CreateRoleAuthMiddleware("seller", "admin"))
+	couponRoutes.POST("" , cartcontroller.CreateCoupon)
+	couponRoutes.GET("" , cartcontroller.GetCoupons)
+	couponRoutes.DELETE("/:code" , cartcontroller.DeactivateCoupon)
+
+	// named carts belong to a signed-in user; guests only have the active cart
+	namedRoutes := r.Group("/named" , cartmiddleware.CreateAuthMiddleware())
+	namedRoutes.POST("" , cartcontroller.CreateNamedCart)
+	namedRoutes.GET("" , cartcontroller.GetNamedCarts)
+	namedRoutes.GET("/:id" , cartcontroller.GetNamedCart)
+	namedRoutes.PATCH("/:id" , cartcontroller.RenameNamedCart)
+	namedRoutes.DELETE("/:id" , cartcontroller.DeleteNamedCart)
+	namedRoutes.POST("/:id/item" , cartcontroller.AddNamedCartItem)
+	namedRoutes.DELETE("/:id/item/:productId" , cartcontroller.RemoveNamedCartItem)
+	namedRoutes.POST("/:id/move-to-cart" , cartcontroller.MoveNamedCartToCart)
+	namedRoutes.POST("/:id/move-from-cart" , cartcontroller.MoveCartToNamedCart)
+
+	// guests are identified by the cart_token cookie when no bearer token is sent
+	cartRoutes := r.Use(cartmiddleware.CreateCartOwnerMiddleware())
+
+	cartRoutes.POST("/item" , cartcontroller.AddItemToCart )
+	cartRoutes.PATCH("/updateitem" , cartcontroller.UpdateItemQuantity)
+	cartRoutes.PATCH("removeitem" , cartcontroller.RemoveItemFromCart)
+	cartRoutes.GET("/get" , cartcontroller.GetCart)
+	cartRoutes.POST("/validate" , cartcontroller.ValidateCart)
+	cartRoutes.DELETE("/clear" , cartcontroller.ClearCart)
+	cartRoutes.POST("/coupon" , cartcontroller.ApplyCoupon)
+	cartRoutes.DELETE("/coupon" , cartcontroller.RemoveCoupon)
+	cartRoutes.POST("/saved" , cartcontroller.SaveForLater)
+	cartRoutes.POST("/saved/move-to-cart" , cartcontroller.MoveSavedToCart)
+	cartRoutes.DELETE("/saved/:productId" , cartcontroller.RemoveSavedItem)
+}
@@ -3,7 +3,22 @@ package db
 import "go.mongodb.org/mongo-driver/mongo"
 
 var cartCollection *mongo.Collection
+var couponCollection *mongo.Collection
+var couponUsageCollection *mongo.Collection
+var namedCartCollection *mongo.Collection
 
 func GetCartCollection() *mongo.Collection {
 	return cartCollection
-}
\ No newline at end of file
+}
+
+func GetCouponCollection() *mongo.Collection {
+	return couponCollection
+}
+
+func GetCouponUsageCollection() *mongo.Collection {
+	return couponUsageCollection
+}
+
+func GetNamedCartCollection() *mongo.Collection {
+	return namedCartCollection
+}
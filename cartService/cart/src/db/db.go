@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -23,5 +24,67 @@ func InitDB() {
 
 	log.Printf("✅ Cart Service Connected to MongoDB") ;
 
-	cartCollection = client.Database("supernovaCartDB").Collection("carts")
+	database := client.Database("supernovaCartDB")
+	cartCollection = database.Collection("carts")
+	couponCollection = database.Collection("coupons")
+	couponUsageCollection = database.Collection("couponUsage")
+	namedCartCollection = database.Collection("namedCarts")
+
+	createIndexes(ctx)
+}
+
+// createIndexes gives each user and each guest a single cart, so concurrent
+// first adds cannot create two, and lets Mongo drop guest carts once
+// expiresAt passes; user carts never set it
+func createIndexes(ctx context.Context) {
+	_, err := cartCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"userId": bson.M{"$type": "objectId"}}),
+		},
+		{
+			Keys:    bson.D{{Key: "guestId", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"guestId": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys: bson.D{{Key: "updatedAt", Value: 1}},
+		},
+	})
+	if err != nil {
+		log.Printf("⚠️ Cart Service failed to create indexes: %v", err)
+	}
+
+	// one usage document per coupon and user is what makes per-user limits atomic
+	_, err = couponCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("⚠️ Cart Service failed to create coupon indexes: %v", err)
+	}
+	_, err = couponUsageCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "couponId", Value: 1}, {Key: "userId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "orderIds", Value: 1}},
+		},
+	})
+	if err != nil {
+		log.Printf("⚠️ Cart Service failed to create coupon usage indexes: %v", err)
+	}
+
+	// a user's named carts are told apart by name
+	_, err = namedCartCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("⚠️ Cart Service failed to create named cart indexes: %v", err)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"supernova/shared/money"
+	"time"
+)
+
+// AbandonedItem is one line of an abandoned cart, priced when the reminder went out
+type AbandonedItem struct {
+	ProductID string      `json:"productId"`
+	Title     string      `json:"title"`
+	SellerID  string      `json:"sellerId,omitempty"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+}
+
+// CartAbandonedEvent is published to CartAbandoned for the reminder email and,
+// without the address, to CartAbandonedDashboard for sellers' abandoned-cart rate.
+// EventID is the same on both so a redelivered message can be recognised.
+type CartAbandonedEvent struct {
+	EventID      string          `json:"eventId"`
+	CartID       string          `json:"cartId"`
+	UserID       string          `json:"userId"`
+	ReceiverMail string          `json:"receiverMail,omitempty"`
+	Items        []AbandonedItem `json:"items"`
+	ReturnURL    string          `json:"returnUrl,omitempty"`
+	IdleSince    time.Time       `json:"idleSince"`
+	AbandonedAt  time.Time       `json:"abandonedAt"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"supernova/shared/money"
+	"time"
+)
+
+// CouponDTO creates a coupon; Value is a percentage or, for fixed coupons,
+// an amount in major units of Currency like MinSpend and MaxDiscount
+type CouponDTO struct {
+	Code         string     `json:"code" binding:"required,min=3,max=32,alphanum"`
+	Type         string     `json:"type" binding:"required,oneof=percentage fixed"`
+	Value        float64    `json:"value" binding:"required,gt=0"`
+	Currency     string     `json:"currency" binding:"required,oneof=USD INR"`
+	MinSpend     float64    `json:"minSpend" binding:"gte=0"`
+	MaxDiscount  float64    `json:"maxDiscount" binding:"gte=0"`
+	UsageLimit   int        `json:"usageLimit" binding:"gte=0"`
+	PerUserLimit int        `json:"perUserLimit" binding:"gte=0"`
+	SellerID     string     `json:"sellerId"`
+	Category     string     `json:"category"`
+	StartsAt     *time.Time `json:"startsAt"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+}
+
+type ApplyCouponDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RedeemCouponDTO is sent by the order service while placing an order
+type RedeemCouponDTO struct {
+	OrderID string `json:"orderId" binding:"required"`
+}
+
+// LineDiscount is the part of a coupon's discount taken off one cart line
+type LineDiscount struct {
+	ProductID string      `json:"productId"`
+	Discount  money.Money `json:"discount"`
+}
+
+// CouponDiscount is the breakdown of the coupon on a cart. When Valid is
+// false Reason says why and no discount applies.
+type CouponDiscount struct {
+	Code             string         `json:"code"`
+	Type             string         `json:"type,omitempty"`
+	Valid            bool           `json:"valid"`
+	Reason           string         `json:"reason,omitempty"`
+	EligibleSubtotal money.Money    `json:"eligibleSubtotal"`
+	Discount         money.Money    `json:"discount"`
+	Lines            []LineDiscount `json:"lines"`
+}
+
+// CouponReleaseEvent is the payload of the CouponRelease queue, published by
+// the order service when an order that redeemed a coupon fails or is cancelled
+type CouponReleaseEvent struct {
+	OrderID string `json:"orderId"`
+	Reason  string `json:"reason,omitempty"`
+}
@@ -1,11 +1,22 @@
 package dto
 
-import (
-	cartmodel "supernova/cartService/cart/src/cartModel"
-)
-
+// Item is what the client sends to add a product; the price is always
+// looked up from the product service, never taken from the request
 type Item struct {
 	ProductID string 			`bson:"productId" json:"productId"`
-	Price     cartmodel.Price  `bson:"price" json:"price"`
 	Quantity  int    			`bson:"quantity" json:"quantity" binding:"required,min=1"`
 }
+
+// ItemQuantity sets a line's quantity. Version is optional; when sent, the
+// change only applies if the cart is still at that version.
+type ItemQuantity struct {
+	ProductID string `json:"productId" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+	Version   *int64 `json:"version"`
+}
+
+// RemoveItem removes a line, with the same optional Version check as ItemQuantity
+type RemoveItem struct {
+	ProductID string `json:"productId" binding:"required"`
+	Version   *int64 `json:"version"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+// MoveItem moves one line between the active cart and the saved for later
+// list, with the same optional Version check as ItemQuantity
+type MoveItem struct {
+	ProductID string `json:"productId" binding:"required"`
+	Version   *int64 `json:"version"`
+}
+
+// NamedCartDTO creates or renames a named cart
+type NamedCartDTO struct {
+	Name string `json:"name" binding:"required,min=1,max=60"`
+}
+
+// MoveLines moves lines between a named cart and the active cart. An empty
+// ProductID moves every line.
+type MoveLines struct {
+	ProductID string `json:"productId"`
+}
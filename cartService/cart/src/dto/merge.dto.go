@@ -0,0 +1,10 @@
+package dto
+
+// MergeConflict reports a guest cart line that could not be merged as asked
+type MergeConflict struct {
+	ProductID string `json:"productId"`
+	Title     string `json:"title,omitempty"`
+	Requested int    `json:"requested"`
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// CartClearEvent is the payload of the CartClear queue, sent by the order
+// service for an order whose cart it could not clear at checkout
+type CartClearEvent struct {
+	OrderID  string    `json:"orderId"`
+	UserID   string    `json:"userId"`
+	PlacedAt time.Time `json:"placedAt"`
+}
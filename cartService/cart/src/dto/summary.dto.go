@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// ExchangeRates is the product service's rate table: units of each currency per one unit of Base
+type ExchangeRates struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// Address is an entry of the auth service address book
+type Address struct {
+	ID         string `json:"id"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// AuthUserResponse is the auth service's reply to /api/auth/user
+type AuthUserResponse struct {
+	UserInfo struct {
+		Addresses []Address `json:"addresses"`
+	} `json:"userInfo"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"supernova/shared/money"
+	"supernova/shared/pricing"
+)
+
+const (
+	// SeverityBlocking issues make CreateOrder fail until they are fixed
+	SeverityBlocking = "blocking"
+	// SeverityWarning issues do not stop checkout but change what is paid
+	SeverityWarning = "warning"
+)
+
+// AddressChoice picks an address the way CreateOrder takes it: an entry of
+// the user's address book by ID, or a full address given inline
+type AddressChoice struct {
+	AddressID string   `json:"addressId"`
+	Address   *Address `json:"address"`
+}
+
+// ValidateCartRequest is the optional body of POST /api/cart/validate and
+// carries the same address choices as the order service's POST /create
+type ValidateCartRequest struct {
+	ShippingAddress *AddressChoice `json:"shippingAddress"`
+	BillingAddress  *AddressChoice `json:"billingAddress"`
+}
+
+// ValidationIssue is one problem found before checkout. Code is stable for
+// clients to switch on; Message is for showing to the shopper.
+type ValidationIssue struct {
+	Severity      string       `json:"severity"`
+	Code          string       `json:"code"`
+	Message       string       `json:"message"`
+	Available     *int         `json:"available,omitempty"`
+	PreviousPrice *money.Money `json:"previousPrice,omitempty"`
+	CurrentPrice  *money.Money `json:"currentPrice,omitempty"`
+}
+
+// LineValidation lists the issues of one cart line
+type LineValidation struct {
+	ProductID string            `json:"productId"`
+	Title     string            `json:"title,omitempty"`
+	Quantity  int               `json:"quantity"`
+	Issues    []ValidationIssue `json:"issues"`
+}
+
+// CartValidation is the reply of POST /api/cart/validate. Valid is false
+// when any line or the cart itself has a blocking issue. Summary is what
+// checkout would charge, when it can be worked out.
+type CartValidation struct {
+	Valid   bool              `json:"valid"`
+	Lines   []LineValidation  `json:"lines"`
+	Issues  []ValidationIssue `json:"issues"`
+	Summary *pricing.Summary  `json:"summary,omitempty"`
+}
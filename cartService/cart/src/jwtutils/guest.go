@@ -0,0 +1,82 @@
+package jwtutils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GuestCookieName holds the signed guest cart token
+const GuestCookieName = "cart_token"
+
+const defaultGuestCartTTL = 7 * 24 * time.Hour
+
+var errInvalidGuestToken = errors.New("invalid guest cart token")
+
+// GuestCartTTL is how long an untouched guest cart (and its cookie) lives
+func GuestCartTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("GUEST_CART_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaultGuestCartTTL
+}
+
+// CheckGuestSecret stops the service at startup when guest tokens would be
+// signed with an empty key, which would let anyone forge a guest cart
+func CheckGuestSecret() {
+	if guestSecret() == "" {
+		log.Fatal("❌ cartService CART_TOKEN_SECRET or JWT_SECRET must be set")
+	}
+}
+
+// GuestCookieSecure reports whether the guest cookie is sent over HTTPS only:
+// COOKIE_SECURE when set, otherwise on in production (GIN_MODE=release)
+func GuestCookieSecure() bool {
+	if secure, err := strconv.ParseBool(os.Getenv("COOKIE_SECURE")); err == nil {
+		return secure
+	}
+	return os.Getenv("GIN_MODE") == "release"
+}
+
+// NewGuestToken creates a random guest ID and the signed token carrying it
+func NewGuestToken() (guestID string, token string, err error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	guestID = hex.EncodeToString(buf)
+	return guestID, guestID + "." + signGuestID(guestID), nil
+}
+
+// ParseGuestToken returns the guest ID if the token's signature is valid
+func ParseGuestToken(token string) (string, error) {
+	guestID, signature, ok := strings.Cut(token, ".")
+	if !ok || guestID == "" {
+		return "", errInvalidGuestToken
+	}
+	if !hmac.Equal([]byte(signature), []byte(signGuestID(guestID))) {
+		return "", errInvalidGuestToken
+	}
+	return guestID, nil
+}
+
+func signGuestID(guestID string) string {
+	mac := hmac.New(sha256.New, []byte(guestSecret()))
+	mac.Write([]byte(guestID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func guestSecret() string {
+	if secret := os.Getenv("CART_TOKEN_SECRET"); secret != "" {
+		return secret
+	}
+	return os.Getenv("JWT_SECRET")
+}
@@ -21,7 +21,7 @@ var (
 	retryBackoff = 5 * time.Second
 )
 
-var queues = []string{"AuthService", "PaymentService" , "ProductCreated"}
+var queues = []string{"AuthService", "PaymentService" , "ProductCreated", "WishlistItemPriceDropped", "WishlistItemBackInStock", "LowStock", "BackInStock", "CartAbandoned", "ShipmentUpdated"}
 
 // Connect initializes RabbitMQ connection and channel (idempotent)
 func Connect() {
@@ -186,7 +186,30 @@ func handleMessage(queue string, msg amqp.Delivery) {
 		var data dto.OrderData
 		_ = json.Unmarshal(msg.Body, &data)
 		controller.OrderPlacedEmail(data)
-	
+	case "WishlistItemPriceDropped":
+		var data dto.WishlistItemData
+		_ = json.Unmarshal(msg.Body, &data)
+		controller.WishlistPriceDroppedEmail(data)
+	case "WishlistItemBackInStock":
+		var data dto.WishlistItemData
+		_ = json.Unmarshal(msg.Body, &data)
+		controller.WishlistBackInStockEmail(data)
+	case "LowStock":
+		var data dto.LowStockData
+		_ = json.Unmarshal(msg.Body, &data)
+		controller.LowStockEmail(data)
+	case "BackInStock":
+		var data dto.BackInStockData
+		_ = json.Unmarshal(msg.Body, &data)
+		controller.BackInStockEmail(data)
+	case "CartAbandoned":
+		var data dto.CartAbandonedData
+		_ = json.Unmarshal(msg.Body, &data)
+		controller.CartAbandonedEmail(data)
+	case "ShipmentUpdated":
+		var data dto.ShipmentUpdatedData
+		_ = json.Unmarshal(msg.Body, &data)
+		controller.ShipmentUpdatedEmail(data)
 	}
 	msg.Ack(false)
 }
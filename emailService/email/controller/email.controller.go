@@ -2,7 +2,9 @@ package controller
 
 import (
 	"fmt"
+	"html"
 	"log"
+	"net/url"
 	"os"
 	"strings"
 	"supernova/emailService/email/dto"
@@ -78,11 +80,11 @@ func PaymentInitiatedEmail(body dto.PaymentData) {
 	plainTextContent := fmt.Sprintf(
 		"Hello %s,\n\n"+
 			"Your payment process has been initiated.\n\n"+
-			"Order ID: %s\nPayment ID: %s\nAmount: %.2f %s\n\n"+
+			"Order ID: %s\nPayment ID: %s\nAmount: %s\n\n"+
 			"You will receive a confirmation once the payment is successfully processed.\n\n"+
 			"Thank you for choosing SUPERNOVA!\n\n"+
 			"Best regards,\nSUPERNOVA Payments Team",
-		receiverName, body.OrderID, body.PaymentID, body.Amount, body.Currency,
+		receiverName, body.OrderID, body.PaymentID, body.Amount,
 	)
 
 	// HTML version
@@ -96,7 +98,7 @@ func PaymentInitiatedEmail(body dto.PaymentData) {
 				<table style="border-collapse: collapse; margin-top: 10px;">
 					<tr><td><strong>Order ID:</strong></td><td>%s</td></tr>
 					<tr><td><strong>Payment ID:</strong></td><td>%s</td></tr>
-					<tr><td><strong>Amount:</strong></td><td>%.2f %s</td></tr>
+					<tr><td><strong>Amount:</strong></td><td>%s</td></tr>
 				</table>
 
 				<p style="margin-top: 15px;">
@@ -111,7 +113,7 @@ func PaymentInitiatedEmail(body dto.PaymentData) {
 				<p>Warm regards,<br><strong>The SUPERNOVA Payments Team</strong></p>
 			</body>
 		</html>`,
-		receiverName, body.OrderID, body.PaymentID, body.Amount, body.Currency,
+		receiverName, body.OrderID, body.PaymentID, body.Amount,
 	)
 
 	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
@@ -145,11 +147,11 @@ func ProductCreatedEmail(body dto.ProductData) {
 	plainTextContent := fmt.Sprintf(
 		"Hello %s,\n\n"+
 			"Your new product has been successfully created in the marketplace.\n\n"+
-			"Product Name: %s\nProduct ID: %s\nPrice: %.2f %s\nCategory: %s\n\n"+
+			"Product Name: %s\nProduct ID: %s\nPrice: %s\n\n"+
 			"Your product is now live and visible to customers.\n\n"+
 			"Thank you for using SUPERNOVA Marketplace!\n\n"+
 			"Best regards,\nSUPERNOVA Marketplace Team",
-		receiverName, body.ProductName, body.ProductID, body.Price, body.Currency,
+		receiverName, body.ProductName, body.ProductID, body.Price,
 	)
 
 	// HTML content
@@ -163,8 +165,7 @@ func ProductCreatedEmail(body dto.ProductData) {
 				<table style="border-collapse: collapse; margin-top: 10px;">
 					<tr><td><strong>Product Name:</strong></td><td>%s</td></tr>
 					<tr><td><strong>Product ID:</strong></td><td>%s</td></tr>
-					<tr><td><strong>Price:</strong></td><td>%.2f %s</td></tr>
-					<tr><td><strong>Category:</strong></td><td>%s</td></tr>
+					<tr><td><strong>Price:</strong></td><td>%s</td></tr>
 				</table>
 
 				<p style="margin-top: 15px;">
@@ -179,7 +180,7 @@ func ProductCreatedEmail(body dto.ProductData) {
 				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
 			</body>
 		</html>`,
-		receiverName, body.ProductName, body.ProductID, body.Price, body.Currency,
+		receiverName, body.ProductName, body.ProductID, body.Price,
 	)
 
 	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
@@ -212,11 +213,11 @@ func OrderPlacedEmail(body dto.OrderData) {
 	plainTextContent := fmt.Sprintf(
 		"Hello %s,\n\n"+
 			"Your order has been successfully placed!\n\n"+
-			"Order ID: %s\nProduct Name: %s\nQuantity: %d\nTotal Amount: %.2f %s\n\n"+
+			"Order ID: %s\nTotal Amount: %s\n\n"+
 			"You will receive a confirmation once the order is processed.\n\n"+
 			"Thank you for shopping with SUPERNOVA Marketplace!\n\n"+
 			"Best regards,\nSUPERNOVA Marketplace Team",
-		receiverName, body.OrderID, body.TotalAmount, body.Currency,
+		receiverName, body.OrderID, body.TotalAmount,
 	)
 
 	// HTML content
@@ -229,9 +230,7 @@ func OrderPlacedEmail(body dto.OrderData) {
 
 				<table style="border-collapse: collapse; margin-top: 10px;">
 					<tr><td><strong>Order ID:</strong></td><td>%s</td></tr>
-					<tr><td><strong>Product Name:</strong></td><td>%s</td></tr>
-					<tr><td><strong>Quantity:</strong></td><td>%d</td></tr>
-					<tr><td><strong>Total Amount:</strong></td><td>%.2f %s</td></tr>
+					<tr><td><strong>Total Amount:</strong></td><td>%s</td></tr>
 				</table>
 
 				<p style="margin-top: 15px;">
@@ -246,7 +245,7 @@ func OrderPlacedEmail(body dto.OrderData) {
 				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
 			</body>
 		</html>`,
-		receiverName, body.OrderID, body.TotalAmount, body.Currency,
+		receiverName, body.OrderID, body.TotalAmount,
 	)
 
 	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
@@ -258,4 +257,438 @@ func OrderPlacedEmail(body dto.OrderData) {
 	} else {
 		log.Printf("🛒 Order placed email sent to %s | Status: %d\n", body.ReceiverMail, response.StatusCode)
 	}
-}
\ No newline at end of file
+}
+func WishlistPriceDroppedEmail(body dto.WishlistItemData) {
+	senderMail := os.Getenv("SENDER_MAIL")
+	sendgridApiKey := os.Getenv("SENDGRID_API_KEY")
+
+	if senderMail == "" || sendgridApiKey == "" {
+		log.Print("❌ SENDGRID_API_KEY or SENDER_MAIL is empty")
+		return
+	}
+
+	receiverName := strings.Split(body.ReceiverMail, "@")[0]
+
+	from := mail.NewEmail("SUPERNOVA Marketplace", senderMail)
+	subject := fmt.Sprintf("Price drop: %s", body.ProductName)
+	to := mail.NewEmail(receiverName, body.ReceiverMail)
+
+	// Plain text content
+	plainTextContent := fmt.Sprintf(
+		"Hello %s,\n\n"+
+			"Good news! A product on your wishlist \"%s\" just got cheaper.\n\n"+
+			"Product Name: %s\nWas: %s\nNow: %s\n\n"+
+			"Grab it before the price changes again.\n\n"+
+			"Best regards,\nSUPERNOVA Marketplace Team",
+		receiverName, body.WishlistName, body.ProductName, body.OldPrice, body.Price,
+	)
+
+	// HTML content
+	htmlContent := fmt.Sprintf(
+		`<html>
+			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+				<h2>Price Drop 🔻</h2>
+				<p>Hi <strong>%s</strong>,</p>
+				<p>A product on your wishlist <strong>%s</strong> just got cheaper.</p>
+
+				<table style="border-collapse: collapse; margin-top: 10px;">
+					<tr><td><strong>Product Name:</strong></td><td>%s</td></tr>
+					<tr><td><strong>Was:</strong></td><td><s>%s</s></td></tr>
+					<tr><td><strong>Now:</strong></td><td>%s</td></tr>
+				</table>
+
+				<p style="margin-top: 15px;">
+					Grab it before the price changes again!
+				</p>
+
+				<br>
+				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
+			</body>
+		</html>`,
+		html.EscapeString(receiverName), html.EscapeString(body.WishlistName), html.EscapeString(body.ProductName),
+		html.EscapeString(body.OldPrice.String()), html.EscapeString(body.Price.String()),
+	)
+
+	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
+	client := sendgrid.NewSendClient(sendgridApiKey)
+
+	response, err := client.Send(message)
+	if err != nil {
+		log.Println("❌ Error sending wishlist price drop email:", err)
+	} else {
+		log.Printf("🔻 Wishlist price drop email sent to %s | Status: %d\n", body.ReceiverMail, response.StatusCode)
+	}
+}
+
+func WishlistBackInStockEmail(body dto.WishlistItemData) {
+	senderMail := os.Getenv("SENDER_MAIL")
+	sendgridApiKey := os.Getenv("SENDGRID_API_KEY")
+
+	if senderMail == "" || sendgridApiKey == "" {
+		log.Print("❌ SENDGRID_API_KEY or SENDER_MAIL is empty")
+		return
+	}
+
+	receiverName := strings.Split(body.ReceiverMail, "@")[0]
+
+	from := mail.NewEmail("SUPERNOVA Marketplace", senderMail)
+	subject := fmt.Sprintf("Back in stock: %s", body.ProductName)
+	to := mail.NewEmail(receiverName, body.ReceiverMail)
+
+	// Plain text content
+	plainTextContent := fmt.Sprintf(
+		"Hello %s,\n\n"+
+			"A product on your wishlist \"%s\" is back in stock.\n\n"+
+			"Product Name: %s\nPrice: %s\n\n"+
+			"Order soon, stock may be limited.\n\n"+
+			"Best regards,\nSUPERNOVA Marketplace Team",
+		receiverName, body.WishlistName, body.ProductName, body.Price,
+	)
+
+	// HTML content
+	htmlContent := fmt.Sprintf(
+		`<html>
+			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+				<h2>Back in Stock 📦</h2>
+				<p>Hi <strong>%s</strong>,</p>
+				<p>A product on your wishlist <strong>%s</strong> is available again.</p>
+
+				<table style="border-collapse: collapse; margin-top: 10px;">
+					<tr><td><strong>Product Name:</strong></td><td>%s</td></tr>
+					<tr><td><strong>Price:</strong></td><td>%s</td></tr>
+				</table>
+
+				<p style="margin-top: 15px;">
+					Order soon, stock may be limited!
+				</p>
+
+				<br>
+				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
+			</body>
+		</html>`,
+		html.EscapeString(receiverName), html.EscapeString(body.WishlistName), html.EscapeString(body.ProductName), html.EscapeString(body.Price.String()),
+	)
+
+	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
+	client := sendgrid.NewSendClient(sendgridApiKey)
+
+	response, err := client.Send(message)
+	if err != nil {
+		log.Println("❌ Error sending wishlist back in stock email:", err)
+	} else {
+		log.Printf("📦 Wishlist back in stock email sent to %s | Status: %d\n", body.ReceiverMail, response.StatusCode)
+	}
+}
+
+func LowStockEmail(body dto.LowStockData) {
+	senderMail := os.Getenv("SENDER_MAIL")
+	sendgridApiKey := os.Getenv("SENDGRID_API_KEY")
+
+	if senderMail == "" || sendgridApiKey == "" {
+		log.Print("❌ SENDGRID_API_KEY or SENDER_MAIL is empty")
+		return
+	}
+
+	receiverName := strings.Split(body.ReceiverMail, "@")[0]
+
+	from := mail.NewEmail("SUPERNOVA Marketplace", senderMail)
+	subject := fmt.Sprintf("Low stock: %s", body.ProductName)
+	to := mail.NewEmail(receiverName, body.ReceiverMail)
+
+	// Plain text content
+	plainTextContent := fmt.Sprintf(
+		"Hello %s,\n\n"+
+			"One of your products is running low on stock.\n\n"+
+			"Product Name: %s\nProduct ID: %s\nStock left: %d\nYour threshold: %d\n\n"+
+			"Restock soon to avoid missing sales.\n\n"+
+			"Best regards,\nSUPERNOVA Marketplace Team",
+		receiverName, body.ProductName, body.ProductID.Hex(), body.Stock, body.Threshold,
+	)
+
+	// HTML content
+	htmlContent := fmt.Sprintf(
+		`<html>
+			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+				<h2>Low Stock ⚠️</h2>
+				<p>Hi <strong>%s</strong>,</p>
+				<p>One of your products is running low on stock.</p>
+
+				<table style="border-collapse: collapse; margin-top: 10px;">
+					<tr><td><strong>Product Name:</strong></td><td>%s</td></tr>
+					<tr><td><strong>Product ID:</strong></td><td>%s</td></tr>
+					<tr><td><strong>Stock left:</strong></td><td>%d</td></tr>
+					<tr><td><strong>Your threshold:</strong></td><td>%d</td></tr>
+				</table>
+
+				<p style="margin-top: 15px;">
+					Restock soon to avoid missing sales.
+				</p>
+
+				<br>
+				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
+			</body>
+		</html>`,
+		html.EscapeString(receiverName), html.EscapeString(body.ProductName), body.ProductID.Hex(), body.Stock, body.Threshold,
+	)
+
+	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
+	client := sendgrid.NewSendClient(sendgridApiKey)
+
+	response, err := client.Send(message)
+	if err != nil {
+		log.Println("❌ Error sending low stock email:", err)
+	} else {
+		log.Printf("⚠️ Low stock email sent to %s | Status: %d\n", body.ReceiverMail, response.StatusCode)
+	}
+}
+
+func BackInStockEmail(body dto.BackInStockData) {
+	senderMail := os.Getenv("SENDER_MAIL")
+	sendgridApiKey := os.Getenv("SENDGRID_API_KEY")
+
+	if senderMail == "" || sendgridApiKey == "" {
+		log.Print("❌ SENDGRID_API_KEY or SENDER_MAIL is empty")
+		return
+	}
+
+	receiverName := strings.Split(body.ReceiverMail, "@")[0]
+
+	from := mail.NewEmail("SUPERNOVA Marketplace", senderMail)
+	subject := fmt.Sprintf("Back in stock: %s", body.ProductName)
+	to := mail.NewEmail(receiverName, body.ReceiverMail)
+
+	// Plain text content
+	plainTextContent := fmt.Sprintf(
+		"Hello %s,\n\n"+
+			"A product you asked about is back in stock.\n\n"+
+			"Product Name: %s\nPrice: %s\n\n"+
+			"Order soon, stock may be limited.\n\n"+
+			"Best regards,\nSUPERNOVA Marketplace Team",
+		receiverName, body.ProductName, body.Price,
+	)
+
+	// HTML content
+	htmlContent := fmt.Sprintf(
+		`<html>
+			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+				<h2>Back in Stock 📦</h2>
+				<p>Hi <strong>%s</strong>,</p>
+				<p>A product you asked about is available again.</p>
+
+				<table style="border-collapse: collapse; margin-top: 10px;">
+					<tr><td><strong>Product Name:</strong></td><td>%s</td></tr>
+					<tr><td><strong>Price:</strong></td><td>%s</td></tr>
+				</table>
+
+				<p style="margin-top: 15px;">
+					Order soon, stock may be limited!
+				</p>
+
+				<br>
+				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
+			</body>
+		</html>`,
+		html.EscapeString(receiverName), html.EscapeString(body.ProductName), html.EscapeString(body.Price.String()),
+	)
+
+	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
+	client := sendgrid.NewSendClient(sendgridApiKey)
+
+	response, err := client.Send(message)
+	if err != nil {
+		log.Println("❌ Error sending back in stock email:", err)
+	} else {
+		log.Printf("📦 Back in stock email sent to %s | Status: %d\n", body.ReceiverMail, response.StatusCode)
+	}
+}
+
+func CartAbandonedEmail(body dto.CartAbandonedData) {
+	senderMail := os.Getenv("SENDER_MAIL")
+	sendgridApiKey := os.Getenv("SENDGRID_API_KEY")
+
+	if senderMail == "" || sendgridApiKey == "" {
+		log.Print("❌ SENDGRID_API_KEY or SENDER_MAIL is empty")
+		return
+	}
+	if len(body.Items) == 0 {
+		return
+	}
+
+	receiverName := strings.Split(body.ReceiverMail, "@")[0]
+
+	from := mail.NewEmail("SUPERNOVA Marketplace", senderMail)
+	subject := "You left something in your cart"
+	to := mail.NewEmail(receiverName, body.ReceiverMail)
+
+	// titles come from sellers, so everything put into the HTML is escaped
+	var textLines, htmlRows strings.Builder
+	for _, item := range body.Items {
+		fmt.Fprintf(&textLines, "- %s x %d (%s each)\n", item.Title, item.Quantity, item.Price)
+		fmt.Fprintf(&htmlRows, "<tr><td>%s</td><td>%d</td><td>%s</td></tr>",
+			html.EscapeString(item.Title), item.Quantity, html.EscapeString(item.Price.String()))
+	}
+	returnText := "Sign in to pick up where you left off."
+	returnHTML := "<p>Sign in to pick up where you left off.</p>"
+	if returnURL, ok := storefrontURL(body.ReturnURL); ok {
+		returnText = fmt.Sprintf("Pick up where you left off: %s", returnURL)
+		returnHTML = fmt.Sprintf(`<p><a href="%s">Return to your cart</a></p>`, html.EscapeString(returnURL))
+	}
+
+	// Plain text content
+	plainTextContent := fmt.Sprintf(
+		"Hello %s,\n\n"+
+			"You still have these items in your cart:\n\n%s\n"+
+			"%s\n\n"+
+			"Prices and stock can change, so don't wait too long.\n\n"+
+			"Best regards,\nSUPERNOVA Marketplace Team",
+		receiverName, textLines.String(), returnText,
+	)
+
+	// HTML content
+	htmlContent := fmt.Sprintf(
+		`<html>
+			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+				<h2>Still thinking it over? 🛒</h2>
+				<p>Hi <strong>%s</strong>,</p>
+				<p>You still have these items in your cart:</p>
+
+				<table style="border-collapse: collapse; margin-top: 10px;">
+					<tr><th align="left">Product</th><th align="left">Qty</th><th align="left">Price</th></tr>
+					%s
+				</table>
+
+				%s
+				<p style="margin-top: 15px;">
+					Prices and stock can change, so don't wait too long!
+				</p>
+
+				<br>
+				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
+			</body>
+		</html>`,
+		html.EscapeString(receiverName), htmlRows.String(), returnHTML,
+	)
+
+	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
+	client := sendgrid.NewSendClient(sendgridApiKey)
+
+	response, err := client.Send(message)
+	if err != nil {
+		log.Println("❌ Error sending abandoned cart email:", err)
+	} else {
+		log.Printf("🛒 Abandoned cart email sent to %s | Status: %d\n", body.ReceiverMail, response.StatusCode)
+	}
+}
+
+// storefrontURL accepts a link for an email only if it is http(s) and points
+// at the store's own host (STOREFRONT_URL), so a forged event cannot make us
+// send shoppers elsewhere
+func storefrontURL(raw string) (string, bool) {
+	if raw == "" {
+		return "", false
+	}
+	store, err := url.Parse(os.Getenv("STOREFRONT_URL"))
+	if err != nil || store.Host == "" {
+		return "", false
+	}
+	link, err := url.Parse(raw)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.User != nil {
+		return "", false
+	}
+	if !strings.EqualFold(link.Host, store.Host) {
+		return "", false
+	}
+	return link.String(), true
+}
+
+// trackingURL only lets a carrier's http or https link into the email
+func trackingURL(raw string) (string, bool) {
+	if raw == "" {
+		return "", false
+	}
+	link, err := url.Parse(raw)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" || link.User != nil {
+		return "", false
+	}
+	return link.String(), true
+}
+
+// shipmentHeadlines says in a few words what a shipment status means for the shopper
+var shipmentHeadlines = map[string]string{
+	"created":          "Your order is being prepared for shipping",
+	"in_transit":       "Your order is on its way",
+	"out_for_delivery": "Your order is out for delivery",
+	"delivered":        "Your order has been delivered",
+	"exception":        "There is a problem with your delivery",
+}
+
+func ShipmentUpdatedEmail(body dto.ShipmentUpdatedData) {
+	senderMail := os.Getenv("SENDER_MAIL")
+	sendgridApiKey := os.Getenv("SENDGRID_API_KEY")
+
+	if senderMail == "" || sendgridApiKey == "" {
+		log.Print("❌ SENDGRID_API_KEY or SENDER_MAIL is empty")
+		return
+	}
+	if body.ReceiverMail == "" {
+		return
+	}
+
+	headline, ok := shipmentHeadlines[body.Status]
+	if !ok {
+		headline = "There is news about your delivery"
+	}
+	receiverName := strings.Split(body.ReceiverMail, "@")[0]
+
+	from := mail.NewEmail("SUPERNOVA Marketplace", senderMail)
+	subject := headline
+	to := mail.NewEmail(receiverName, body.ReceiverMail)
+
+	details := body.Description
+	if body.Location != "" {
+		details = strings.TrimSpace(details + " (" + body.Location + ")")
+	}
+	carrier := strings.ToUpper(body.Carrier)
+	trackText := fmt.Sprintf("Tracking number: %s (%s)", body.TrackingNumber, carrier)
+	trackHTML := fmt.Sprintf("<p>Tracking number: <strong>%s</strong> (%s)</p>", html.EscapeString(body.TrackingNumber), html.EscapeString(carrier))
+	if link, ok := trackingURL(body.TrackingURL); ok {
+		trackText += fmt.Sprintf("\nTrack your parcel: %s", link)
+		trackHTML += fmt.Sprintf(`<p><a href="%s">Track your parcel</a></p>`, html.EscapeString(link))
+	}
+
+	// Plain text content
+	plainTextContent := fmt.Sprintf(
+		"Hello %s,\n\n"+
+			"%s.\n%s\n\n"+
+			"Order ID: %s\n%s\n\n"+
+			"Best regards,\nSUPERNOVA Marketplace Team",
+		receiverName, headline, details, body.OrderID, trackText,
+	)
+
+	// HTML content
+	htmlContent := fmt.Sprintf(
+		`<html>
+			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+				<h2>%s 📦</h2>
+				<p>Hi <strong>%s</strong>,</p>
+				<p>%s</p>
+				<p>Order ID: <strong>%s</strong></p>
+				%s
+
+				<br>
+				<p>Warm regards,<br><strong>The SUPERNOVA Marketplace Team</strong></p>
+			</body>
+		</html>`,
+		headline, html.EscapeString(receiverName), html.EscapeString(details), html.EscapeString(body.OrderID), trackHTML,
+	)
+
+	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
+	client := sendgrid.NewSendClient(sendgridApiKey)
+
+	response, err := client.Send(message)
+	if err != nil {
+		log.Println("❌ Error sending shipment update email:", err)
+	} else {
+		log.Printf("📦 Shipment update email sent to %s | Status: %d\n", body.ReceiverMail, response.StatusCode)
+	}
+}
@@ -1,6 +1,11 @@
 package dto
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"supernova/shared/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type JsonUser struct {
 	Name  string `json:"name"`
@@ -11,22 +16,77 @@ type PaymentData struct {
 	ReceiverMail string 			`json:"receiverMail"`
 	PaymentID  	primitive.ObjectID `json:"paymentID"`
 	OrderID		primitive.ObjectID `json:"orderID"`
-	Amount 		float64				`json:"amount"`
-	Currency    string				`json:"currency"`
+	Amount 		money.Money			`json:"amount"`
 }
 
 type ProductData struct {
     ReceiverMail string			`json:"receiverMail"`
-    ProductName  string			`json:"receiverMail"`
-    ProductID    string			`json:"receiverMail"`
-    Price        float64		`json:"receiverMail"`
-    Currency     string			`json:"receiverMail"`
+    ProductName  string			`json:"productName"`
+    ProductID    string			`json:"productId"`
+    Price        money.Money	`json:"price"`
 }
 
 
 type OrderData struct {
 	ReceiverMail string
 	OrderID      primitive.ObjectID
-	TotalAmount  float64
-	Currency     string
+	TotalAmount  money.Money
+}
+
+// WishlistItemData is sent by wishlistService when a saved product gets
+// cheaper or comes back in stock
+type WishlistItemData struct {
+	ReceiverMail string             `json:"receiverMail"`
+	WishlistID   primitive.ObjectID `json:"wishlistId"`
+	WishlistName string             `json:"wishlistName"`
+	ProductID    primitive.ObjectID `json:"productId"`
+	ProductName  string             `json:"productName"`
+	OldPrice     money.Money        `json:"oldPrice"`
+	Price        money.Money        `json:"price"`
+}
+
+// LowStockData is sent by productService when stock falls below the seller's threshold
+type LowStockData struct {
+	ReceiverMail string             `json:"receiverMail"`
+	ProductID    primitive.ObjectID `json:"productId"`
+	ProductName  string             `json:"productName"`
+	Stock        int                `json:"stock"`
+	Threshold    int                `json:"threshold"`
+}
+
+// BackInStockData is sent by productService to each restock subscriber
+type BackInStockData struct {
+	ReceiverMail string             `json:"receiverMail"`
+	ProductID    primitive.ObjectID `json:"productId"`
+	ProductName  string             `json:"productName"`
+	Price        money.Money        `json:"price"`
+}
+
+// CartAbandonedItem is one line of an abandoned cart
+type CartAbandonedItem struct {
+	ProductID string      `json:"productId"`
+	Title     string      `json:"title"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+}
+
+// CartAbandonedData is sent by cartService when a signed-in shopper's cart sits idle
+type CartAbandonedData struct {
+	ReceiverMail string              `json:"receiverMail"`
+	Items        []CartAbandonedItem `json:"items"`
+	ReturnURL    string              `json:"returnUrl"`
+}
+
+// ShipmentUpdatedData is sent by orderService when a parcel is created or its carrier reports progress
+type ShipmentUpdatedData struct {
+	ReceiverMail   string    `json:"receiverMail"`
+	ShipmentID     string    `json:"shipmentId"`
+	OrderID        string    `json:"orderId"`
+	Carrier        string    `json:"carrier"`
+	TrackingNumber string    `json:"trackingNumber"`
+	TrackingURL    string    `json:"trackingUrl"`
+	Status         string    `json:"status"`
+	Description    string    `json:"description"`
+	Location       string    `json:"location"`
+	OccurredAt     time.Time `json:"occurredAt"`
 }
@@ -9,4 +9,5 @@ import (
 func SetupEmailApp(router *gin.Engine){
 
 	broaker.Connect()
+	broaker.ConsumeQueues()
 }
\ No newline at end of file
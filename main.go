@@ -11,6 +11,7 @@ import (
 	"supernova/paymentService/payment"
 	"supernova/productService/product"
 	sellerdashboard "supernova/sellerDashboardService/sellerDashboard"
+	"supernova/wishlistService/wishlist"
 
 	"github.com/gin-gonic/gin"
 	ginprometheus "github.com/zsais/go-gin-prometheus"
@@ -69,6 +70,8 @@ func extractService(path string) string {
 		return "email"
 	case strings.HasPrefix(path, "/seller"):
 		return "seller"
+	case strings.HasPrefix(path, "/wishlist"):
+		return "wishlist"
 	default:
 		return "unknown"
 	}
@@ -95,6 +98,7 @@ func main() {
 	payment.SetupPaymentApp(router)
 	email.SetupEmailApp(router)
 	sellerdashboard.SetupSellerDashboardApp(router)
+	wishlist.SetupWishlistApp(router)
 
 	// 4️⃣ run server
 	router.Run(":8080")
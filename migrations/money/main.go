@@ -0,0 +1,144 @@
+// Command money converts stored prices from float64 major units to the
+// integer minor units of shared/money.Money.
+//
+// Every update filters on documents whose amounts are still doubles, so the
+// migration is safe to run more than once. Existing data is only ever in
+// USD or INR, both of which have two minor-unit digits.
+//
+//	MONGO_URI=mongodb://... go run ./migrations/money [-dry-run]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// step is one collection's conversion
+type step struct {
+	db         string
+	collection string
+	filter     bson.M
+	pipeline   mongo.Pipeline
+}
+
+// toMinor turns a double major-unit amount into a long minor-unit amount.
+// The double goes through $toDecimal first so 2.675 is scaled as the decimal
+// it was entered as, not 267.49999..., and $round with zero places then
+// rounds half to even, matching shared/money.FromMajor.
+func toMinor(field string) bson.M {
+	return bson.M{"$toLong": bson.M{"$round": bson.A{
+		bson.M{"$multiply": bson.A{bson.M{"$toDecimal": bson.M{"$ifNull": bson.A{field, 0}}}, 100}}, 0,
+	}}}
+}
+
+// isDouble is true while the amount at field has not been converted yet
+func isDouble(field string) bson.M {
+	return bson.M{"$eq": bson.A{bson.M{"$type": field}, "double"}}
+}
+
+// convertMoney converts the money object at field unless its amount already
+// is a long, so documents that mix old and new prices are only half converted
+func convertMoney(field string) bson.M {
+	return bson.M{"$cond": bson.A{
+		isDouble(field + ".amount"),
+		bson.M{
+			"amount":   toMinor(field + ".amount"),
+			"currency": field + ".currency",
+		},
+		field,
+	}}
+}
+
+// convertItems rewrites items[].price and fills items[].settlementPrice
+// for orders placed before checkout recorded it. Each item is converted on
+// its own: a cart can hold lines written by the new cart service next to
+// old ones, and a re-run after a partial run must not scale a price twice.
+func convertItems(withSettlement bool) bson.M {
+	converted := convertMoney("$$item.price")
+	fields := bson.M{"price": converted}
+	if withSettlement {
+		fields["settlementPrice"] = bson.M{"$ifNull": bson.A{
+			convertMoney("$$item.settlementPrice"),
+			converted,
+		}}
+	}
+	return bson.M{"$map": bson.M{
+		"input": "$items",
+		"as":    "item",
+		"in":    bson.M{"$mergeObjects": bson.A{"$$item", fields}},
+	}}
+}
+
+func setStage(fields bson.M) bson.D {
+	return bson.D{{Key: "$set", Value: fields}}
+}
+
+func steps() []step {
+	productPrice := mongo.Pipeline{setStage(bson.M{"price.amount": toMinor("$price.amount")})}
+	order := mongo.Pipeline{setStage(bson.M{
+		"totalPrice.amount": toMinor("$totalPrice.amount"),
+		"items":             convertItems(true),
+	})}
+	// payments stored the total under an untagged TotalAmount field
+	payment := mongo.Pipeline{
+		setStage(bson.M{"price": bson.M{
+			"amount":   toMinor("$price.totalamount"),
+			"currency": "$price.currency",
+		}}),
+	}
+	doubleAmount := func(field string) bson.M { return bson.M{field: bson.M{"$type": "double"}} }
+
+	return []step{
+		{"SupernovaProductDB", "products", doubleAmount("price.amount"), productPrice},
+		{"supernovaCartDB", "carts", doubleAmount("items.price.amount"), mongo.Pipeline{setStage(bson.M{"items": convertItems(false)})}},
+		{"SupernovaOrderDB", "orders", doubleAmount("totalPrice.amount"), order},
+		{"SupernovaPaymentDB", "payment", bson.M{"price.totalamount": bson.M{"$exists": true}}, payment},
+		{"SupernovaSellerDashboardDB", "product", doubleAmount("price.amount"), productPrice},
+		{"SupernovaSellerDashboardDB", "order", doubleAmount("totalPrice.amount"), order},
+		{"SupernovaSellerDashboardDB", "payment", bson.M{"price.totalamount": bson.M{"$exists": true}}, payment},
+	}
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only count the documents that would change")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️  No .env file found, using system environment")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv("MONGO_URI")))
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	for _, s := range steps() {
+		collection := client.Database(s.db).Collection(s.collection)
+		if *dryRun {
+			count, err := collection.CountDocuments(ctx, s.filter)
+			if err != nil {
+				log.Fatalf("❌ %s.%s: %v", s.db, s.collection, err)
+			}
+			log.Printf("🔎 %s.%s: %d documents to convert", s.db, s.collection, count)
+			continue
+		}
+
+		result, err := collection.UpdateMany(ctx, s.filter, s.pipeline)
+		if err != nil {
+			log.Fatalf("❌ %s.%s: %v", s.db, s.collection, err)
+		}
+		log.Printf("✅ %s.%s: converted %d documents", s.db, s.collection, result.ModifiedCount)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"supernova/shared/money"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testCollection runs the pipelines on the mongod at MONGO_URI, so rounding
+// and $type checks are the server's own; it is dropped when the test ends
+func testCollection(t *testing.T) *mongo.Collection {
+	t.Helper()
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	collection := client.Database("supernovaMigrationTest").Collection(fmt.Sprintf("money_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		collection.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	return collection
+}
+
+// migrate runs a step's update on every document of the collection
+func migrate(t *testing.T, collection *mongo.Collection, pipeline mongo.Pipeline) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := collection.UpdateMany(ctx, bson.M{}, pipeline); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+}
+
+func TestToMinorMatchesFromMajor(t *testing.T) {
+	collection := testCollection(t)
+
+	tests := []struct {
+		major float64
+		minor int64
+	}{
+		{19.99, 1999},
+		{0.01, 1},
+		{0, 0},
+		{1.005, 100}, // tie, rounds to even
+		{1.015, 102}, // tie, rounds to even; 1.015*100 as a double is 101.4999...
+		{2.675, 268}, // 2.675*100 as a double is 267.4999...
+		{0.125, 12},  // tie below one cent
+		{-1.005, -100},
+		{-2.675, -268},
+		{-19.99, -1999},
+		{12345678901.23, 1234567890123},
+		{99999999999.99, 9999999999999},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i, tt := range tests {
+		if _, err := collection.InsertOne(ctx, bson.M{"_id": i, "price": bson.M{"amount": tt.major, "currency": "USD"}}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	migrate(t, collection, mongo.Pipeline{setStage(bson.M{"price.amount": toMinor("$price.amount")})})
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(tt.major), func(t *testing.T) {
+			var doc struct {
+				Price struct {
+					Amount interface{} `bson:"amount"`
+				} `bson:"price"`
+			}
+			if err := collection.FindOne(ctx, bson.M{"_id": i}).Decode(&doc); err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if doc.Price.Amount != tt.minor {
+				t.Errorf("toMinor(%v) = %v (%T), want %d", tt.major, doc.Price.Amount, doc.Price.Amount, tt.minor)
+			}
+			if from := money.FromMajor(tt.major, money.USD).Amount; from != tt.minor {
+				t.Errorf("money.FromMajor(%v) = %d, want %d", tt.major, from, tt.minor)
+			}
+		})
+	}
+}
+
+func TestToMinorMissingAmountIsZero(t *testing.T) {
+	collection := testCollection(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := collection.InsertOne(ctx, bson.M{"_id": 1}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	migrate(t, collection, mongo.Pipeline{setStage(bson.M{"amount": toMinor("$price.amount")})})
+
+	var doc bson.M
+	if err := collection.FindOne(ctx, bson.M{"_id": 1}).Decode(&doc); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if doc["amount"] != int64(0) {
+		t.Errorf("toMinor of a missing amount = %v, want 0", doc["amount"])
+	}
+}
+
+// itemPrices reads back the given money field of every item of document id
+func itemPrices(t *testing.T, collection *mongo.Collection, id int, field string) []bson.M {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var doc struct {
+		Items []bson.M `bson:"items"`
+	}
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	var prices []bson.M
+	for _, item := range doc.Items {
+		price, _ := item[field].(bson.M)
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+func TestConvertItemsOnlyConvertsDoubles(t *testing.T) {
+	collection := testCollection(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := collection.InsertOne(ctx, bson.M{"_id": 1, "items": bson.A{
+		bson.M{"productId": "old", "price": bson.M{"amount": 19.99, "currency": "USD"}},
+		bson.M{"productId": "new", "price": bson.M{"amount": int64(1999), "currency": "USD"}},
+	}})
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	// running it twice is what a re-run after a partial run does
+	for run := 1; run <= 2; run++ {
+		migrate(t, collection, mongo.Pipeline{setStage(bson.M{"items": convertItems(false)})})
+		for i, price := range itemPrices(t, collection, 1, "price") {
+			if price["amount"] != int64(1999) || price["currency"] != "USD" {
+				t.Errorf("run %d: item %d price = %v, want 1999 USD", run, i, price)
+			}
+		}
+	}
+}
+
+func TestConvertItemsSettlementPrice(t *testing.T) {
+	collection := testCollection(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := collection.InsertOne(ctx, bson.M{"_id": 1, "items": bson.A{
+		// placed before checkout recorded a settlement price
+		bson.M{"price": bson.M{"amount": 5.5, "currency": "USD"}},
+		// settlement price still a double
+		bson.M{"price": bson.M{"amount": 5.5, "currency": "USD"}, "settlementPrice": bson.M{"amount": 458.59, "currency": "INR"}},
+		// already converted
+		bson.M{"price": bson.M{"amount": int64(550), "currency": "USD"}, "settlementPrice": bson.M{"amount": int64(45859), "currency": "INR"}},
+	}})
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	want := []bson.M{
+		{"amount": int64(550), "currency": "USD"},
+		{"amount": int64(45859), "currency": "INR"},
+		{"amount": int64(45859), "currency": "INR"},
+	}
+
+	migrate(t, collection, mongo.Pipeline{setStage(bson.M{"items": convertItems(true)})})
+	for i, settlement := range itemPrices(t, collection, 1, "settlementPrice") {
+		if settlement["amount"] != want[i]["amount"] || settlement["currency"] != want[i]["currency"] {
+			t.Errorf("item %d settlementPrice = %v, want %v", i, settlement, want[i])
+		}
+	}
+}
@@ -2,8 +2,10 @@ package order
 
 import (
 	"log"
+	"supernova/orderService/order/src/controller"
 	"supernova/orderService/order/src/db"
 	"supernova/orderService/order/src/routes"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -17,6 +19,8 @@ func SetupOrderApp(router *gin.Engine) {
 
 	db.InitDB()
 	log.Print("order service")
+	go controller.StartOutboxRelay(5 * time.Second)
+	go controller.StartSagaRecovery(time.Minute)
 
 	routes.SetupOrderRoutes(router)
 
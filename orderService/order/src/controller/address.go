@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"supernova/orderService/order/src/dto"
+	"supernova/orderService/order/src/orderModel"
+)
+
+// resolveAddress turns a choice into the address snapshot stored on the
+// order. An ID must be in the user's address book; an inline address must be
+// complete. A nil choice means fallback, or the default address when fallback is nil.
+func resolveAddress(book []dto.Address, choice *dto.AddressChoice, fallback *ordermodel.Address, kind string) (ordermodel.Address, error) {
+	if choice == nil {
+		if fallback != nil {
+			return *fallback, nil
+		}
+		if len(book) == 0 {
+			return ordermodel.Address{}, fmt.Errorf("User must have a %s address configured.", kind)
+		}
+		return checkAddress(ordermodel.Address(book[0]), kind)
+	}
+
+	if choice.AddressID != "" && choice.Address != nil {
+		return ordermodel.Address{}, fmt.Errorf("Give either an addressId or an address for the %s address, not both", kind)
+	}
+	if choice.AddressID != "" {
+		for _, entry := range book {
+			if entry.ID == choice.AddressID {
+				return checkAddress(ordermodel.Address(entry), kind)
+			}
+		}
+		return ordermodel.Address{}, fmt.Errorf("The %s address %s is not in your address book", kind, choice.AddressID)
+	}
+	if choice.Address != nil {
+		address := ordermodel.Address(*choice.Address)
+		address.ID = ""
+		return checkAddress(address, kind)
+	}
+	return ordermodel.Address{}, fmt.Errorf("Give an addressId or an address for the %s address", kind)
+}
+
+// checkAddress rejects an address missing a field shipping or tax needs
+func checkAddress(address ordermodel.Address, kind string) (ordermodel.Address, error) {
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"street", address.Street},
+		{"city", address.City},
+		{"state", address.State},
+		{"country", address.Country},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return ordermodel.Address{}, errors.New("The " + kind + " address is missing its " + strings.Join(missing, ", "))
+	}
+	return address, nil
+}
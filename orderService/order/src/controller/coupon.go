@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"supernova/orderService/order/src/broker"
+	"supernova/orderService/order/src/dto"
+)
+
+// redeemCoupon has the cart service re-check the cart's coupon and count it
+// against its limits for this order. It returns the HTTP status to answer
+// with when the coupon can't be used.
+func redeemCoupon(client *http.Client, token string, orderID string) (dto.CouponDiscount, int, error) {
+	body, err := json.Marshal(dto.CouponRedeemRequest{OrderID: orderID})
+	if err != nil {
+		return dto.CouponDiscount{}, http.StatusInternalServerError, fmt.Errorf("failed to encode coupon request")
+	}
+
+	req, err := http.NewRequest("POST", os.Getenv("CART_SERVICE_URL")+"/api/cart/coupon/redeem", bytes.NewReader(body))
+	if err != nil {
+		return dto.CouponDiscount{}, http.StatusInternalServerError, fmt.Errorf("failed to create coupon request")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Service-Token", os.Getenv("SERVICE_TOKEN"))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return dto.CouponDiscount{}, http.StatusServiceUnavailable, fmt.Errorf("failed to connect to cart service")
+	}
+	defer resp.Body.Close()
+
+	var redeemResp dto.CouponRedeemResponse
+	_ = json.NewDecoder(resp.Body).Decode(&redeemResp)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return redeemResp.Discount, http.StatusOK, nil
+	case http.StatusConflict, http.StatusNotFound:
+		return dto.CouponDiscount{}, http.StatusConflict, fmt.Errorf("coupon can't be used: %s", redeemResp.Error)
+	default:
+		return dto.CouponDiscount{}, http.StatusBadGateway, fmt.Errorf("cart service failed with status: %d", resp.StatusCode)
+	}
+}
+
+// releaseCoupon gives back the coupon redemption of an order that failed or was cancelled
+func releaseCoupon(orderID string, reason string) {
+	body, err := json.Marshal(dto.CouponReleaseEvent{OrderID: orderID, Reason: reason})
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	if err := broker.PublishJSON("CouponRelease", body); err != nil {
+		log.Printf("error: %v", err)
+	}
+}
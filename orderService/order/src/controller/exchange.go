@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"supernova/orderService/order/src/dto"
+)
+
+// fetchExchangeRates reads the current rate table from the product service
+func fetchExchangeRates(client *http.Client) (dto.ExchangeRates, error) {
+	var rates dto.ExchangeRates
+
+	resp, err := client.Get(os.Getenv("PRODUCT_SERVICE_URL") + "/api/product/rates")
+	if err != nil {
+		return rates, fmt.Errorf("failed to connect to product service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rates, fmt.Errorf("product service failed with status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rates); err != nil {
+		return rates, fmt.Errorf("failed to decode exchange rates")
+	}
+	return rates, nil
+}
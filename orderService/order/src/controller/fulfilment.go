@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"supernova/orderService/order/src/db"
+	"supernova/orderService/order/src/dto"
+	"supernova/orderService/order/src/orderModel"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fulfilmentAttempts is how often a seller's update is retried when another
+// seller changed the same order in between
+const fulfilmentAttempts = 3
+
+var errOrderChanged = errors.New("order changed since it was read")
+
+// statusRank orders the statuses an active group goes through
+var statusRank = map[ordermodel.OrderStatus]int{
+	ordermodel.StatusPending:   0,
+	ordermodel.StatusConfirmed: 1,
+	ordermodel.StatusShipped:   2,
+	ordermodel.StatusDelivered: 3,
+}
+
+// statusPath lists the statuses by rank
+var statusPath = []ordermodel.OrderStatus{
+	ordermodel.StatusPending,
+	ordermodel.StatusConfirmed,
+	ordermodel.StatusShipped,
+	ordermodel.StatusDelivered,
+}
+
+// newFulfilmentGroups makes one pending group per seller, in the order the
+// sellers first appear among the items
+func newFulfilmentGroups(items []ordermodel.Item, now time.Time) []ordermodel.FulfilmentGroup {
+	var groups []ordermodel.FulfilmentGroup
+	seen := map[string]bool{}
+	for _, item := range items {
+		if seen[item.SellerID] {
+			continue
+		}
+		seen[item.SellerID] = true
+		groups = append(groups, ordermodel.FulfilmentGroup{
+			SellerID:  item.SellerID,
+			Status:    ordermodel.StatusPending,
+			UpdatedAt: now,
+		})
+	}
+	return groups
+}
+
+// setGroupStatus moves group to status and stamps when it happened
+func setGroupStatus(group *ordermodel.FulfilmentGroup, status ordermodel.OrderStatus, now time.Time) {
+	group.Status = status
+	group.UpdatedAt = now
+	stamp := now
+	switch status {
+	case ordermodel.StatusConfirmed:
+		group.ConfirmedAt = &stamp
+	case ordermodel.StatusShipped:
+		group.ShippedAt = &stamp
+	case ordermodel.StatusDelivered:
+		group.DeliveredAt = &stamp
+	case ordermodel.StatusCancelled:
+		group.CancelledAt = &stamp
+	}
+}
+
+// advanceGroups moves every group that can make the transition to status,
+// leaving the ones already past it or cancelled alone
+func advanceGroups(groups []ordermodel.FulfilmentGroup, status ordermodel.OrderStatus, now time.Time) []ordermodel.FulfilmentGroup {
+	advanced := append([]ordermodel.FulfilmentGroup(nil), groups...)
+	for i := range advanced {
+		if isValidStatusTransition(advanced[i].Status, status) {
+			setGroupStatus(&advanced[i], status, now)
+		}
+	}
+	return advanced
+}
+
+// advanceGroupTo walks group along the status path until it reaches target,
+// stamping each step, and reports whether it moved. Cancelled groups stay.
+func advanceGroupTo(group *ordermodel.FulfilmentGroup, target ordermodel.OrderStatus, now time.Time) bool {
+	if group.Status == ordermodel.StatusCancelled {
+		return false
+	}
+	moved := false
+	for statusRank[group.Status] < statusRank[target] {
+		setGroupStatus(group, statusPath[statusRank[group.Status]+1], now)
+		moved = true
+	}
+	return moved
+}
+
+// deriveOrderStatus is the status of the least advanced group still active,
+// so an order only counts as shipped once every seller has shipped. An order
+// whose groups are all cancelled is cancelled; one without groups keeps current.
+func deriveOrderStatus(groups []ordermodel.FulfilmentGroup, current ordermodel.OrderStatus) ordermodel.OrderStatus {
+	if len(groups) == 0 {
+		return current
+	}
+	derived := ordermodel.StatusCancelled
+	for _, group := range groups {
+		if group.Status == ordermodel.StatusCancelled {
+			continue
+		}
+		if derived == ordermodel.StatusCancelled || statusRank[group.Status] < statusRank[derived] {
+			derived = group.Status
+		}
+	}
+	return derived
+}
+
+// saveOrderProgress stores order's status and groups unless the order changed
+// since it was read at readAt. The dashboard is told in the same transaction,
+// together with any extra events.
+func saveOrderProgress(ctx context.Context, order ordermodel.Order, readAt time.Time, events []ordermodel.OutboxEvent) error {
+	statusEvent, err := orderProgressEvent(order)
+	if err != nil {
+		return err
+	}
+	events = append(events, statusEvent)
+
+	err = db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if err := writeOrderProgress(sessCtx, order, readAt); err != nil {
+			return err
+		}
+		return enqueueEvents(sessCtx, events)
+	})
+	if err != nil {
+		return err
+	}
+	wakeOutboxRelay()
+	return nil
+}
+
+// writeOrderProgress is the order update of saveOrderProgress, for callers
+// that write more in the same transaction
+func writeOrderProgress(sessCtx mongo.SessionContext, order ordermodel.Order, readAt time.Time) error {
+	set := bson.M{
+		"status":    order.Status,
+		"updatedAt": order.UpdatedAt,
+	}
+	if len(order.Fulfilments) > 0 {
+		set["fulfilments"] = order.Fulfilments
+	}
+	result, err := db.GetOrderCollection().UpdateOne(sessCtx,
+		bson.M{"_id": order.OrderID, "updatedAt": readAt},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errOrderChanged
+	}
+	return nil
+}
+
+// orderProgressEvent tells the seller dashboard about order's status and groups
+func orderProgressEvent(order ordermodel.Order) (ordermodel.OutboxEvent, error) {
+	return newOutboxEvent(order.OrderID, "OrderStatusDashboard", dto.OrderStatusEvent{
+		OrderID:     order.OrderID.Hex(),
+		Status:      order.Status,
+		Fulfilments: order.Fulfilments,
+		UpdatedAt:   order.UpdatedAt,
+	}, 0)
+}
+
+// UpdateFulfilmentStatus lets a seller move their own group of an order on.
+// Cancelling stays with the shopper, as it releases the whole order.
+func UpdateFulfilmentStatus(c *gin.Context) {
+	orderObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+		return
+	}
+	sellerID := c.GetString("UserID")
+
+	var update dto.FulfilmentUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if _, ok := statusRank[update.Status]; !ok || update.Status == ordermodel.StatusPending {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid fulfilment status",
+			"validStatuses": []ordermodel.OrderStatus{
+				ordermodel.StatusConfirmed,
+				ordermodel.StatusShipped,
+				ordermodel.StatusDelivered,
+			},
+		})
+		return
+	}
+	if update.Tracking != nil && (update.Tracking.Carrier == "" || update.Tracking.Number == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tracking needs a carrier and a number"})
+		return
+	}
+
+	for attempt := 0; attempt < fulfilmentAttempts; attempt++ {
+		order, group, status, err := advanceSellerGroup(orderObjectID, sellerID, update)
+		if errors.Is(err, errOrderChanged) {
+			continue
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found or has no items from you"})
+			return
+		}
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Fulfilment updated successfully",
+			"orderId":     order.OrderID,
+			"orderStatus": order.Status,
+			"fulfilment":  group,
+		})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "Order changed while it was being updated, please retry"})
+}
+
+// advanceSellerGroup applies update to sellerID's group of the order and
+// returns the saved order and group, or an error with the status to answer with
+func advanceSellerGroup(orderID primitive.ObjectID, sellerID string, update dto.FulfilmentUpdate) (ordermodel.Order, ordermodel.FulfilmentGroup, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var order ordermodel.Order
+	err := db.GetOrderCollection().FindOne(ctx, bson.M{"_id": orderID, "fulfilments.sellerId": sellerID}).Decode(&order)
+	if err != nil {
+		return order, ordermodel.FulfilmentGroup{}, http.StatusInternalServerError, err
+	}
+
+	index := -1
+	for i, group := range order.Fulfilments {
+		if group.SellerID == sellerID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return order, ordermodel.FulfilmentGroup{}, http.StatusNotFound, mongo.ErrNoDocuments
+	}
+	current := order.Fulfilments[index].Status
+	if !isValidStatusTransition(current, update.Status) {
+		return order, ordermodel.FulfilmentGroup{}, http.StatusBadRequest,
+			fmt.Errorf("Invalid status transition from %s to %s", current, update.Status)
+	}
+
+	now := time.Now()
+	readAt := order.UpdatedAt
+	order.Fulfilments = append([]ordermodel.FulfilmentGroup(nil), order.Fulfilments...)
+	group := &order.Fulfilments[index]
+	setGroupStatus(group, update.Status, now)
+	if update.Tracking != nil {
+		group.Tracking = update.Tracking
+	}
+	order.Status = deriveOrderStatus(order.Fulfilments, order.Status)
+	order.UpdatedAt = now
+
+	if err := saveOrderProgress(ctx, order, readAt, nil); err != nil {
+		return order, *group, http.StatusInternalServerError, err
+	}
+	return order, *group, http.StatusOK, nil
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"supernova/orderService/order/src/broker"
+	"supernova/orderService/order/src/dto"
+	ordermodel "supernova/orderService/order/src/orderModel"
+)
+
+// reserveInventory holds stock for every order item in the product service.
+// It returns the HTTP status to answer with when the reservation fails.
+func reserveInventory(client *http.Client, token string, order ordermodel.Order) (int, error) {
+	reserveReq := dto.ReserveRequest{
+		OrderID: order.OrderID.Hex(),
+		Items:   make([]dto.ReserveItem, 0, len(order.Items)),
+	}
+	for _, item := range order.Items {
+		reserveReq.Items = append(reserveReq.Items, dto.ReserveItem{
+			ProductID: item.ProductID.Hex(),
+			Quantity:  item.Quantity,
+		})
+	}
+	body, err := json.Marshal(reserveReq)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to encode reservation request")
+	}
+
+	req, err := http.NewRequest("POST", os.Getenv("PRODUCT_SERVICE_URL")+"/api/product/inventory/reserve", bytes.NewReader(body))
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to create reservation request")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", os.Getenv("SERVICE_TOKEN"))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("failed to connect to product service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		var reserveErr dto.ReserveErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&reserveErr)
+		if reserveErr.ProductID != "" {
+			return http.StatusConflict, fmt.Errorf("insufficient stock for product %s", reserveErr.ProductID)
+		}
+		return http.StatusConflict, fmt.Errorf("failed to reserve stock: %s", reserveErr.Error)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return http.StatusBadGateway, fmt.Errorf("product service failed with status: %d", resp.StatusCode)
+	}
+	return http.StatusCreated, nil
+}
+
+// releaseInventory asks the product service to return an order's reserved stock
+func releaseInventory(orderID string, reason string) {
+	body, err := json.Marshal(dto.InventoryEvent{OrderID: orderID, Reason: reason})
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	if err := broker.PublishJSON("InventoryRelease", body); err != nil {
+		log.Printf("error: %v", err)
+	}
+}
@@ -3,15 +3,18 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 
 	"net/http"
 	"os"
-	"supernova/orderService/order/src/broker"
 	"supernova/orderService/order/src/db"
 	"supernova/orderService/order/src/dto"
 	"supernova/orderService/order/src/orderModel"
+	"supernova/shared/money"
+	"supernova/shared/pricing"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -55,6 +58,13 @@ func CreateOrder(c *gin.Context) {
 		return
 	}
 
+	// The body is optional; without it the order ships to and is billed to the default address
+	var orderRequest dto.CreateOrderRequest
+	if err := c.ShouldBindJSON(&orderRequest); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order request", "details": err.Error()})
+		return
+	}
+
 	client := http.Client{Timeout: 10 * time.Second}
 	cartServiceURL := os.Getenv("CART_SERVICE_URL")
 	authServiceURL := os.Getenv("AUTH_SERVICE_URL")
@@ -135,111 +145,178 @@ func CreateOrder(c *gin.Context) {
 	}
 	user := authResp.UserInfo
 
-	// Check if user has at least one address
-	if len(user.Addresses) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User must have a shipping address configured."})
+	address, err := resolveAddress(user.Addresses, orderRequest.ShippingAddress, nil, "shipping")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	billingAddress, err := resolveAddress(user.Addresses, orderRequest.BillingAddress, &address, "billing")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	var address dto.Address
-	address = user.Addresses[0]
 
 	// ----------------------------------------------------
 	// 4. Calculate Total and Build Order Model
 	// ----------------------------------------------------
 
-	var totalAmount float64
-	currency := userCart.Items[0].Price.Currency
-	
-	orderItems := make([]ordermodel.Item, 0, len(userCart.Items)) // preallocate slice
+	rates, err := fetchExchangeRates(&client)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
 
-	for _, item := range userCart.Items {
-		// Calculate total
-		totalAmount += item.Price.Amount * float64(item.Quantity)
-		// Convert dto.Item → ordermodel.Item and append
-		orderItems = append(orderItems, ordermodel.Item{
-			ProductID: item.ProductID,
-			Price: ordermodel.Price{
-				Amount:   item.Price.Amount,
-				Currency: ordermodel.Currency(item.Price.Currency),
-			},
-			Quantity: item.Quantity,
-		})
+	// Price every line as of now; the cart's stored price may predate a sale
+	quote, err := quotePrices(&client, userCart.Items)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
 	}
 
+	currency := pricing.SettlementCurrency()
+
 	var order ordermodel.Order
 	// Assign a new ObjectID here, as it's the MongoDB primary key
 	order.OrderID = primitive.NewObjectID()
-	order.UserID = userObjectID
-	order.Items = orderItems // Spread operator to convert slice types
-	order.TotalPrice = ordermodel.Price{
-		Amount:totalAmount,
-		Currency: currency,
-	}
-	order.Status = ordermodel.StatusPending // Directly assign constant
-	order.Address = ordermodel.Address(address)
-	order.CreatedAt = time.Now()
-	order.UpdatedAt = time.Now()
-
-	// ----------------------------------------------------
-	// 5. Save to Database
-	// ----------------------------------------------------
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
 
-	orderCollection := db.GetOrderCollection()
-	result, err := orderCollection.InsertOne(ctx, order)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order in database", "details": err.Error()})
+	// Placing the order is a saga: redeem the coupon, reserve stock, then
+	// commit the order with its events. A failed step compensates the ones
+	// before it.
+	if err := startSaga(order.OrderID, userObjectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start order", "details": err.Error()})
 		return
 	}
 
-	cartClearReq, err := http.NewRequest("DELETE", cartServiceURL+"/api/cart/clear", nil)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create cart clear request"})
-		return
+	// The coupon is checked again against the prices above and redeemed for this order
+	lineDiscounts := map[string]money.Money{}
+	couponCode := ""
+	if userCart.CouponCode != "" {
+		if err := beginSagaStep(order.OrderID, ordermodel.StepRedeemCoupon); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record order progress", "details": err.Error()})
+			return
+		}
+		couponDiscount, status, err := redeemCoupon(&client, tokenStr, order.OrderID.Hex())
+		if err != nil {
+			compensateSaga(order.OrderID, "coupon redemption failed")
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		couponCode = couponDiscount.Code
+		for _, line := range couponDiscount.Lines {
+			lineDiscounts[line.ProductID] = line.Discount
+		}
 	}
-	cartClearReq.Header.Set("Authorization", "Bearer "+tokenStr)
 
-	cartClearResp, err := client.Do(cartClearReq)
-	if err != nil || cartClearResp.StatusCode != http.StatusOK {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to clear cart after order creation"})
+	// Totals come from the same calculation the cart shows the shopper
+	pricingLines := make([]pricing.Line, 0, len(userCart.Items))
+	for _, item := range userCart.Items {
+		productID := item.ProductID.Hex()
+		pricingLines = append(pricingLines, pricing.Line{
+			ProductID: productID,
+			UnitPrice: quote[productID].UnitPrice,
+			Quantity:  item.Quantity,
+			Discount:  lineDiscounts[productID],
+		})
+	}
+	summary, err := pricing.Calculate(pricingLines, currency, func(from money.Currency) (float64, error) {
+		return pricing.ExchangeRate(rates.Rates, from, currency)
+	}, &pricing.Destination{Country: address.Country, State: address.State})
+	if err != nil {
+		compensateSaga(order.OrderID, "order pricing failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer cartClearResp.Body.Close()
 
-	orderJson ,err := json.Marshal(&order)
-	if err != nil {
-		log.Print("error: %v" , err.Error())
+	orderItems := make([]ordermodel.Item, 0, len(userCart.Items)) // preallocate slice
+	for i, item := range userCart.Items {
+		line := quote[item.ProductID.Hex()]
+		priced := summary.Lines[i]
+		orderItems = append(orderItems, ordermodel.Item{
+			ProductID:       item.ProductID,
+			SellerID:        line.SellerID,
+			Price:           line.UnitPrice,
+			SettlementPrice: priced.UnitPrice,
+			Discount:        priced.Discount,
+			Tax:             priced.Tax,
+			Quantity:        item.Quantity,
+			SaleID:          line.SaleID,
+		})
 	}
-	err  = broker.PublishJSON("OrderDashboard" , orderJson)
-	if err != nil {
-		log.Print("error: %v" , err.Error())
+	if couponCode != "" {
+		order.Coupon = &ordermodel.AppliedCoupon{
+			Code:     couponCode,
+			Discount: summary.Discount,
+		}
 	}
-	
 
+	order.UserID = userObjectID
+	order.ContactEmail = userEmailStr
+	order.Items = orderItems // Spread operator to convert slice types
+	order.Subtotal = summary.Subtotal
+	order.Tax = summary.Tax
+	order.Shipping = summary.Shipping
+	order.TotalPrice = summary.GrandTotal
+	order.ExchangeRates = summary.ExchangeRates
+	order.Status = ordermodel.StatusPending // Directly assign constant
+	order.Address = address
+	order.BillingAddress = billingAddress
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = time.Now()
+	order.Fulfilments = newFulfilmentGroups(orderItems, order.CreatedAt)
+
+	// ----------------------------------------------------
+	// 5. Reserve Stock (Product Service)
+	// ----------------------------------------------------
+	if err := beginSagaStep(order.OrderID, ordermodel.StepReserveStock); err != nil {
+		compensateSaga(order.OrderID, "order progress could not be recorded")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record order progress", "details": err.Error()})
+		return
+	}
+	if status, err := reserveInventory(&client, tokenStr, order); err != nil {
+		compensateSaga(order.OrderID, "stock reservation failed")
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
 
+	// ----------------------------------------------------
+	// 6. Save the order with its events (outbox)
+	// ----------------------------------------------------
 	orderData := dto.OrderData{
 		ReceiverMail: userEmailStr,
-		OrderID: result.InsertedID.(primitive.ObjectID),
-		TotalAmount: totalAmount,
-		Currency: currency ,
+		OrderID:      order.OrderID,
+		TotalAmount:  order.TotalPrice,
 	}
-	orderDataJson ,err := json.Marshal(&orderData)
+	events, cartClearEvent, err := orderCreatedEvents(order, orderData)
 	if err != nil {
-		log.Print("error: %v" , err.Error())
+		compensateSaga(order.OrderID, "order events could not be encoded")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode order events", "details": err.Error()})
+		return
+	}
+	if err := commitOrder(order, events); err != nil {
+		compensateSaga(order.OrderID, "order creation failed")
+		if errors.Is(err, errSagaFinished) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Order placement timed out, please try again"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order in database", "details": err.Error()})
+		return
 	}
+	wakeOutboxRelay()
 
-	err = broker.PublishJSON("OrderCreated" , orderDataJson)
-	if err != nil {
-		log.Print("error: %v" , err.Error())
+	// ----------------------------------------------------
+	// 7. Clear the cart; the outbox retries it if this fails
+	// ----------------------------------------------------
+	if err := clearCart(&client, tokenStr); err != nil {
+		log.Printf("⚠️ orderService cart clear for order %s left to the outbox: %v", order.OrderID.Hex(), err)
+	} else {
+		settleOutboxEvent(cartClearEvent.ID)
 	}
 
 	// Final success response
 	c.JSON(http.StatusCreated, gin.H{
 		"message":       "Order created successfully",
 		"order_details": order,
-		"inserted_id":   result.InsertedID,
+		"inserted_id":   order.OrderID,
 	})
 }
 
@@ -331,6 +408,23 @@ func GetOrderByID(c *gin.Context) {
 	})
 }
 
+// checkCancellable is the one rule for a shopper cancelling an order: only
+// while it is pending and no seller has started on their part of it
+func checkCancellable(order ordermodel.Order) error {
+	if order.Status == ordermodel.StatusCancelled {
+		return errors.New("Order is already cancelled")
+	}
+	if order.Status != ordermodel.StatusPending {
+		return errors.New("Only pending orders can be cancelled")
+	}
+	for _, group := range order.Fulfilments {
+		if group.Status != ordermodel.StatusPending && group.Status != ordermodel.StatusCancelled {
+			return errors.New("A seller has already confirmed part of this order, so it can no longer be cancelled")
+		}
+	}
+	return nil
+}
+
 func CancleOrderByID(c *gin.Context) {
 	orderID := c.Param("id")
 	if orderID == "" {
@@ -357,12 +451,6 @@ func CancleOrderByID(c *gin.Context) {
 	defer cancel()
 	orderCollection := db.GetOrderCollection()
 	filter := bson.M{"_id": orderObjectID, "userId": userObjectID}
-	update := bson.M{
-		"$set": bson.M{
-			"status":    ordermodel.StatusCancelled,
-			"updatedAt": time.Now(),
-		},
-	}
 	var orderDoc ordermodel.Order
 	err = orderCollection.FindOne(ctx, filter).Decode(&orderDoc)
 	if err != nil {
@@ -374,24 +462,32 @@ func CancleOrderByID(c *gin.Context) {
 		return
 	}
 
-	if orderDoc.Status == ordermodel.StatusCancelled {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order is already cancelled"})
-		return
-	}
-	if orderDoc.Status != ordermodel.StatusPending {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Only pending orders can be cancelled"})
+	if err := checkCancellable(orderDoc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	result, err := orderCollection.UpdateOne(ctx, filter, update)
+	// the status change and the releases it triggers are committed together
+	events, err := releaseEvents(orderDoc, "order cancelled")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order in database", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode order events", "details": err.Error()})
 		return
 	}
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found or does not belong to the user"})
+	now := time.Now()
+	readAt := orderDoc.UpdatedAt
+	orderDoc.Fulfilments = advanceGroups(orderDoc.Fulfilments, ordermodel.StatusCancelled, now)
+	orderDoc.Status = ordermodel.StatusCancelled
+	orderDoc.UpdatedAt = now
+	err = saveOrderProgress(ctx, orderDoc, readAt, events)
+	if errors.Is(err, errOrderChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order changed while it was being cancelled, please retry"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order in database", "details": err.Error()})
 		return
 	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Order cancelled successfully",
 	})
@@ -423,9 +519,17 @@ func UpdateOrderAddress(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address data", "details": err.Error()})
 		return
 	}
+	// an address given here is inline, not an address book entry
+	address := ordermodel.Address(addressDTO)
+	address.ID = ""
+	address, err = checkAddress(address, "shipping")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	update := bson.M{
 		"$set": bson.M{
-			"address":   ordermodel.Address(addressDTO),
+			"address":   address,
 			"updatedAt": time.Now(),
 		},
 	}
@@ -433,7 +537,8 @@ func UpdateOrderAddress(c *gin.Context) {
 	defer cancle()
 	orderCollection := db.GetOrderCollection()
 
-	filter := bson.M{"userId": userObjectID, "_id": orderObjectID}
+	// once sellers start preparing the order the address can no longer change
+	filter := bson.M{"userId": userObjectID, "_id": orderObjectID, "status": ordermodel.StatusPending}
 
 	result, err := orderCollection.UpdateOne(ctx, filter, update)
 
@@ -442,8 +547,19 @@ func UpdateOrderAddress(c *gin.Context) {
 			"error":   "Failed to update address",
 			"details": err.Error(),
 		})
+		return
 	}
 	if result.MatchedCount == 0 {
+		delete(filter, "status")
+		count, err := orderCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update address", "details": err.Error()})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Only pending orders can change their address"})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found or does not belong to the user"})
 		return
 	}
@@ -485,7 +601,8 @@ func isValidStatusTransition(currentStatus, newStatus ordermodel.OrderStatus) bo
 	return false
 }
 
-// UpdateOrderStatus updates the status of an existing order
+// UpdateOrderStatus lets the shopper who placed an order cancel it. Every
+// other move is made per seller through UpdateFulfilmentStatus.
 func UpdateOrderStatus(c *gin.Context) {
 	// Get the order ID from the URL parameter
 	orderID := c.Param("id")
@@ -532,6 +649,12 @@ func UpdateOrderStatus(c *gin.Context) {
 		})
 		return
 	}
+	if updateRequest.Status != ordermodel.StatusCancelled {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You can only cancel an order; sellers update their part of it through /api/order/seller/fulfilment/:id",
+		})
+		return
+	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("UserID")
@@ -561,50 +684,35 @@ func UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	isAdminVal, exists := c.Get("IsAdmin")
-	isAdmin, ok := isAdminVal.(bool)
-
-	if !exists || !ok || !isAdmin {
-		// not admin — so only allow if user owns the order
-		if currentOrder.UserID != userObjectID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this order"})
-			return
-		}
+	if currentOrder.UserID != userObjectID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this order"})
+		return
 	}
 
-	
-
-	// Validate status transition
-	if !isValidStatusTransition(currentOrder.Status, updateRequest.Status) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Invalid status transition from %s to %s",
-				currentOrder.Status, updateRequest.Status),
-		})
+	if err := checkCancellable(currentOrder); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Create update document
-	update := bson.M{
-		"$set": bson.M{
-			"status":    updateRequest.Status,
-			"updatedAt": time.Now(),
-		},
+	events, err := releaseEvents(currentOrder, "order cancelled")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode order events"})
+		return
 	}
 
-	// Update the order
-	filter := bson.M{"_id": orderObjectID}
-	if !isAdmin {
-		filter["userId"] = userObjectID 
-	}
+	// every group is cancelled along with the order
+	now := time.Now()
+	updatedOrder := currentOrder
+	updatedOrder.Fulfilments = advanceGroups(currentOrder.Fulfilments, ordermodel.StatusCancelled, now)
+	updatedOrder.Status = ordermodel.StatusCancelled
+	updatedOrder.UpdatedAt = now
 
-	result, err := collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
+	err = saveOrderProgress(ctx, updatedOrder, currentOrder.UpdatedAt, events)
+	if errors.Is(err, errOrderChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order changed while it was being updated, please retry"})
 		return
 	}
-
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found or unauthorized"})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
 		return
 	}
 
@@ -612,6 +720,6 @@ func UpdateOrderStatus(c *gin.Context) {
 		"message":   "Order status updated successfully",
 		"orderId":   orderID,
 		"oldStatus": currentOrder.Status,
-		"newStatus": updateRequest.Status,
+		"newStatus": updatedOrder.Status,
 	})
 }
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"supernova/orderService/order/src/broker"
+	"supernova/orderService/order/src/db"
+	ordermodel "supernova/orderService/order/src/orderModel"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// outboxLease is how long a relay holds a row it is publishing before
+	// another relay may pick it up again
+	outboxLease              = 30 * time.Second
+	maxOutboxBackoff         = 10 * time.Minute
+	defaultOutboxMaxAttempts = 20
+)
+
+// outboxWake lets a request that just wrote outbox rows start the relay
+// without waiting for its next tick
+var outboxWake = make(chan struct{}, 1)
+
+// newOutboxEvent encodes payload for queue; the relay publishes it once delay has passed
+func newOutboxEvent(orderID primitive.ObjectID, queue string, payload interface{}, delay time.Duration) (ordermodel.OutboxEvent, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return ordermodel.OutboxEvent{}, err
+	}
+	now := time.Now()
+	return ordermodel.OutboxEvent{
+		ID:          primitive.NewObjectID(),
+		OrderID:     orderID,
+		Queue:       queue,
+		Body:        body,
+		Status:      ordermodel.OutboxPending,
+		AvailableAt: now.Add(delay),
+		CreatedAt:   now,
+	}, nil
+}
+
+// enqueueEvents writes events to the outbox; call it with the session
+// context of the transaction that makes the change they announce
+func enqueueEvents(ctx context.Context, events []ordermodel.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		docs = append(docs, event)
+	}
+	_, err := db.GetOutboxCollection().InsertMany(ctx, docs)
+	return err
+}
+
+// settleOutboxEvent marks a row as done when its work already happened some
+// other way, so the relay does not publish it
+func settleOutboxEvent(eventID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := db.GetOutboxCollection().UpdateOne(ctx,
+		bson.M{"_id": eventID, "status": ordermodel.OutboxPending},
+		bson.M{"$set": bson.M{"status": ordermodel.OutboxPublished, "publishedAt": now}},
+	)
+	if err != nil {
+		log.Printf("⚠️ orderService failed to settle outbox event %s: %v", eventID.Hex(), err)
+	}
+}
+
+func wakeOutboxRelay() {
+	select {
+	case outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartOutboxRelay publishes due outbox rows to RabbitMQ every interval, or
+// sooner when woken. A failed publish is retried with exponential backoff
+// until OUTBOX_MAX_ATTEMPTS, after which the row is marked failed.
+func StartOutboxRelay(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		relayOutbox()
+		select {
+		case <-ticker.C:
+		case <-outboxWake:
+		}
+	}
+}
+
+func relayOutbox() {
+	for {
+		event, ok := claimOutboxEvent()
+		if !ok {
+			return
+		}
+		publishOutboxEvent(event)
+	}
+}
+
+// claimOutboxEvent takes the oldest due row and leases it, so several
+// relays can run without publishing the same row at the same time
+func claimOutboxEvent() (ordermodel.OutboxEvent, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var event ordermodel.OutboxEvent
+	err := db.GetOutboxCollection().FindOneAndUpdate(ctx,
+		bson.M{"status": ordermodel.OutboxPending, "availableAt": bson.M{"$lte": now}},
+		bson.M{
+			"$set": bson.M{"availableAt": now.Add(outboxLease)},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "availableAt", Value: 1}, {Key: "_id", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&event)
+	if err == mongo.ErrNoDocuments {
+		return event, false
+	}
+	if err != nil {
+		log.Printf("❌ orderService outbox claim failed: %v", err)
+		return event, false
+	}
+	return event, true
+}
+
+func publishOutboxEvent(event ordermodel.OutboxEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	outbox := db.GetOutboxCollection()
+	filter := bson.M{"_id": event.ID, "status": ordermodel.OutboxPending}
+	if err := broker.PublishJSON(event.Queue, event.Body); err != nil {
+		set := bson.M{
+			"lastError":   err.Error(),
+			"availableAt": time.Now().Add(outboxBackoff(event.Attempts)),
+		}
+		if event.Attempts >= outboxMaxAttempts() {
+			set["status"] = ordermodel.OutboxFailed
+			log.Printf("❌ orderService gave up publishing %s for order %s after %d attempts: %v", event.Queue, event.OrderID.Hex(), event.Attempts, err)
+		}
+		if _, err := outbox.UpdateOne(ctx, filter, bson.M{"$set": set}); err != nil {
+			log.Printf("❌ orderService failed to record outbox failure %s: %v", event.ID.Hex(), err)
+		}
+		return
+	}
+
+	now := time.Now()
+	_, err := outbox.UpdateOne(ctx, filter, bson.M{
+		"$set":   bson.M{"status": ordermodel.OutboxPublished, "publishedAt": now},
+		"$unset": bson.M{"lastError": ""},
+	})
+	if err != nil {
+		// the row is published again once the lease runs out, so delivery is
+		// at least once
+		log.Printf("⚠️ orderService published %s but failed to mark it: %v", event.ID.Hex(), err)
+	}
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempts && backoff < maxOutboxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxOutboxBackoff {
+		return maxOutboxBackoff
+	}
+	return backoff
+}
+
+func outboxMaxAttempts() int {
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultOutboxMaxAttempts
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"supernova/orderService/order/src/dto"
+	ordermodel "supernova/orderService/order/src/orderModel"
+)
+
+// quotePrices asks the product service what each cart line costs at order time,
+// so sales that started or ended since the item was added are honoured
+func quotePrices(client *http.Client, items []ordermodel.Item) (map[string]dto.QuoteLine, error) {
+	quoteReq := dto.QuoteRequest{Items: make([]dto.QuoteItem, 0, len(items))}
+	for _, item := range items {
+		quoteReq.Items = append(quoteReq.Items, dto.QuoteItem{
+			ProductID: item.ProductID.Hex(),
+			Quantity:  item.Quantity,
+		})
+	}
+	body, err := json.Marshal(quoteReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode quote request")
+	}
+
+	resp, err := client.Post(os.Getenv("PRODUCT_SERVICE_URL")+"/api/product/quote", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to product service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product service failed with status: %d", resp.StatusCode)
+	}
+
+	var quote dto.QuoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return nil, fmt.Errorf("failed to decode quote")
+	}
+	if len(quote.Missing) > 0 {
+		return nil, fmt.Errorf("products no longer available: %v", quote.Missing)
+	}
+
+	lines := make(map[string]dto.QuoteLine, len(quote.Items))
+	for _, line := range quote.Items {
+		lines[line.ProductID] = line
+	}
+	return lines, nil
+}
@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"supernova/orderService/order/src/db"
+	"supernova/orderService/order/src/dto"
+	ordermodel "supernova/orderService/order/src/orderModel"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// cartClearDelay gives CreateOrder time to clear the cart itself before
+	// the outbox falls back to asking the cart service through the queue
+	cartClearDelay     = 30 * time.Second
+	defaultSagaTimeout = 2 * time.Minute
+)
+
+// errSagaFinished means the recovery sweep already compensated the saga
+var errSagaFinished = errors.New("order saga is no longer running")
+
+// startSaga records that placing orderID has begun
+func startSaga(orderID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := db.GetSagaCollection().InsertOne(ctx, ordermodel.OrderSaga{
+		OrderID:   orderID,
+		UserID:    userID,
+		Status:    ordermodel.SagaRunning,
+		Steps:     []ordermodel.SagaStep{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	return err
+}
+
+// beginSagaStep records step before it runs
+func beginSagaStep(orderID primitive.ObjectID, step ordermodel.SagaStep) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := db.GetSagaCollection().UpdateOne(ctx,
+		bson.M{"_id": orderID, "status": ordermodel.SagaRunning},
+		bson.M{
+			"$addToSet": bson.M{"steps": step},
+			"$set":      bson.M{"updatedAt": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errSagaFinished
+	}
+	return nil
+}
+
+// commitOrder is the saga's pivot: the order, the events announcing it and
+// the fallback cart clear are written in one transaction, and the saga is
+// completed with them. Everything after this is retried, never undone.
+func commitOrder(order ordermodel.Order, events []ordermodel.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		res, err := db.GetSagaCollection().UpdateOne(sessCtx,
+			bson.M{"_id": order.OrderID, "status": ordermodel.SagaRunning},
+			bson.M{
+				"$addToSet": bson.M{"steps": ordermodel.StepCreateOrder},
+				"$set":      bson.M{"status": ordermodel.SagaCompleted, "updatedAt": time.Now()},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return errSagaFinished
+		}
+		if _, err := db.GetOrderCollection().InsertOne(sessCtx, order); err != nil {
+			return err
+		}
+		return enqueueEvents(sessCtx, events)
+	})
+}
+
+// compensateSaga undoes the steps a failed saga began, latest first, by
+// queueing their release events in the same transaction that marks the saga
+// compensated. A saga that already finished is left alone.
+func compensateSaga(orderID primitive.ObjectID, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var saga ordermodel.OrderSaga
+	err := db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		err := db.GetSagaCollection().FindOneAndUpdate(sessCtx,
+			bson.M{"_id": orderID, "status": ordermodel.SagaRunning},
+			bson.M{"$set": bson.M{
+				"status":    ordermodel.SagaCompensated,
+				"failure":   reason,
+				"updatedAt": time.Now(),
+			}},
+		).Decode(&saga)
+		if err != nil {
+			return err
+		}
+		events, err := compensationEvents(saga, reason)
+		if err != nil {
+			return err
+		}
+		return enqueueEvents(sessCtx, events)
+	})
+	if err == mongo.ErrNoDocuments {
+		return
+	}
+	if err != nil {
+		// without the order DB the releases can only be sent straight to the broker
+		log.Printf("❌ orderService failed to compensate order %s through the outbox: %v", orderID.Hex(), err)
+		releaseInventory(orderID.Hex(), reason)
+		releaseCoupon(orderID.Hex(), reason)
+		return
+	}
+	wakeOutboxRelay()
+}
+
+func compensationEvents(saga ordermodel.OrderSaga, reason string) ([]ordermodel.OutboxEvent, error) {
+	var events []ordermodel.OutboxEvent
+	for i := len(saga.Steps) - 1; i >= 0; i-- {
+		var event ordermodel.OutboxEvent
+		var err error
+		switch saga.Steps[i] {
+		case ordermodel.StepReserveStock:
+			event, err = newOutboxEvent(saga.OrderID, "InventoryRelease", dto.InventoryEvent{OrderID: saga.OrderID.Hex(), Reason: reason}, 0)
+		case ordermodel.StepRedeemCoupon:
+			event, err = newOutboxEvent(saga.OrderID, "CouponRelease", dto.CouponReleaseEvent{OrderID: saga.OrderID.Hex(), Reason: reason}, 0)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// orderCreatedEvents are the events committed with a new order. The cart
+// clear is delayed so the relay only sends it when clearCart did not manage.
+func orderCreatedEvents(order ordermodel.Order, orderData dto.OrderData) ([]ordermodel.OutboxEvent, ordermodel.OutboxEvent, error) {
+	var events []ordermodel.OutboxEvent
+	for _, queued := range []struct {
+		queue   string
+		payload interface{}
+	}{
+		{"OrderDashboard", order},
+		// product service builds "frequently bought together" from these
+		{"OrderRecommendation", order},
+		{"OrderCreated", orderData},
+	} {
+		event, err := newOutboxEvent(order.OrderID, queued.queue, queued.payload, 0)
+		if err != nil {
+			return nil, ordermodel.OutboxEvent{}, err
+		}
+		events = append(events, event)
+	}
+
+	cartClear, err := newOutboxEvent(order.OrderID, "CartClear", dto.CartClearEvent{
+		OrderID:  order.OrderID.Hex(),
+		UserID:   order.UserID.Hex(),
+		PlacedAt: order.CreatedAt,
+	}, cartClearDelay)
+	if err != nil {
+		return nil, ordermodel.OutboxEvent{}, err
+	}
+	return append(events, cartClear), cartClear, nil
+}
+
+// releaseEvents give back what a cancelled order holds: its reserved stock and its coupon
+func releaseEvents(order ordermodel.Order, reason string) ([]ordermodel.OutboxEvent, error) {
+	inventory, err := newOutboxEvent(order.OrderID, "InventoryRelease", dto.InventoryEvent{OrderID: order.OrderID.Hex(), Reason: reason}, 0)
+	if err != nil {
+		return nil, err
+	}
+	events := []ordermodel.OutboxEvent{inventory}
+	if order.Coupon != nil {
+		coupon, err := newOutboxEvent(order.OrderID, "CouponRelease", dto.CouponReleaseEvent{OrderID: order.OrderID.Hex(), Reason: reason}, 0)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, coupon)
+	}
+	return events, nil
+}
+
+// clearCart empties the shopper's cart right after the order is committed.
+// If it fails the CartClear outbox row does it later.
+func clearCart(client *http.Client, token string) error {
+	req, err := http.NewRequest("DELETE", os.Getenv("CART_SERVICE_URL")+"/api/cart/clear", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("cart service answered " + resp.Status)
+	}
+	return nil
+}
+
+// StartSagaRecovery finishes sagas left running by a request that died part
+// way, for example in a restart. Once SAGA_TIMEOUT has passed without
+// progress the saga is compensated; an order that was committed would have
+// completed it already.
+func StartSagaRecovery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		cursor, err := db.GetSagaCollection().Find(ctx, bson.M{
+			"status":    ordermodel.SagaRunning,
+			"updatedAt": bson.M{"$lt": time.Now().Add(-durationFromEnv("SAGA_TIMEOUT", defaultSagaTimeout))},
+		}, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			log.Println("❌ orderService saga recovery failed:", err)
+			cancel()
+			continue
+		}
+		var stuck []ordermodel.OrderSaga
+		if err := cursor.All(ctx, &stuck); err != nil {
+			log.Println("❌ orderService failed to decode stuck sagas:", err)
+		}
+		cancel()
+
+		for _, saga := range stuck {
+			log.Printf("⚠️ orderService compensating stuck order saga %s", saga.OrderID.Hex())
+			compensateSaga(saga.OrderID, "order placement timed out")
+		}
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
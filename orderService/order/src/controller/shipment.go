@@ -0,0 +1,462 @@
+package controller
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"supernova/orderService/order/src/db"
+	"supernova/orderService/order/src/dto"
+	"supernova/orderService/order/src/orderModel"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// carrierSignatureHeader carries the hex HMAC-SHA256 of the webhook body,
+// keyed with CARRIER_WEBHOOK_SECRET and optionally prefixed "sha256="
+const carrierSignatureHeader = "X-Carrier-Signature"
+
+var (
+	errShipmentExists  = errors.New("tracking number already registered")
+	errShipmentChanged = errors.New("shipment changed since it was read")
+)
+
+var carrierStatuses = map[ordermodel.ShipmentStatus]bool{
+	ordermodel.ShipmentInTransit:      true,
+	ordermodel.ShipmentOutForDelivery: true,
+	ordermodel.ShipmentDelivered:      true,
+	ordermodel.ShipmentException:      true,
+}
+
+// CreateShipment lets a seller register a parcel for their group of an order.
+// The group must be confirmed; carrier updates then move it to shipped and delivered.
+func CreateShipment(c *gin.Context) {
+	orderObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+		return
+	}
+	sellerID := c.GetString("UserID")
+
+	var request dto.CreateShipmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	request.Carrier = normalizeCarrier(request.Carrier)
+	request.TrackingNumber = strings.TrimSpace(request.TrackingNumber)
+	if request.Carrier == "" || request.TrackingNumber == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Carrier and tracking number are required"})
+		return
+	}
+	// the link ends up in the shopper's email, so only web links are taken
+	if request.TrackingURL != "" && !isWebURL(request.TrackingURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tracking URL must be an http or https link"})
+		return
+	}
+
+	for attempt := 0; attempt < fulfilmentAttempts; attempt++ {
+		shipment, status, err := addShipment(orderObjectID, sellerID, request)
+		if errors.Is(err, errOrderChanged) {
+			continue
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found or has no items from you"})
+			return
+		}
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{
+			"message":  "Shipment created successfully",
+			"shipment": shipment,
+		})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "Order changed while the shipment was being created, please retry"})
+}
+
+// addShipment stores the shipment and the group's latest tracking together,
+// returning an error with the status to answer with
+func addShipment(orderID primitive.ObjectID, sellerID string, request dto.CreateShipmentRequest) (ordermodel.Shipment, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var order ordermodel.Order
+	err := db.GetOrderCollection().FindOne(ctx, bson.M{"_id": orderID, "fulfilments.sellerId": sellerID}).Decode(&order)
+	if err != nil {
+		return ordermodel.Shipment{}, http.StatusInternalServerError, err
+	}
+	group := sellerGroup(&order, sellerID)
+	if group == nil {
+		return ordermodel.Shipment{}, http.StatusNotFound, mongo.ErrNoDocuments
+	}
+	if group.Status != ordermodel.StatusConfirmed && group.Status != ordermodel.StatusShipped {
+		return ordermodel.Shipment{}, http.StatusBadRequest,
+			errors.New("Only confirmed or shipped items can get a shipment, this part of the order is " + string(group.Status))
+	}
+
+	now := time.Now()
+	shipment := ordermodel.Shipment{
+		ID:             primitive.NewObjectID(),
+		OrderID:        order.OrderID,
+		UserID:         order.UserID,
+		SellerID:       sellerID,
+		Carrier:        request.Carrier,
+		TrackingNumber: request.TrackingNumber,
+		TrackingURL:    request.TrackingURL,
+		Status:         ordermodel.ShipmentCreated,
+		Events: []ordermodel.ShipmentEvent{{
+			Status:      ordermodel.ShipmentCreated,
+			Description: "Shipment created by the seller",
+			OccurredAt:  now,
+			ReceivedAt:  now,
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	readAt := order.UpdatedAt
+	group.Tracking = &ordermodel.Tracking{Carrier: shipment.Carrier, Number: shipment.TrackingNumber, URL: shipment.TrackingURL}
+	group.UpdatedAt = now
+	order.UpdatedAt = now
+
+	events, err := shipmentEvents(order, shipment, true)
+	if err != nil {
+		return shipment, http.StatusInternalServerError, err
+	}
+
+	err = db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if _, err := db.GetShipmentCollection().InsertOne(sessCtx, shipment); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return errShipmentExists
+			}
+			return err
+		}
+		if err := writeOrderProgress(sessCtx, order, readAt); err != nil {
+			return err
+		}
+		return enqueueEvents(sessCtx, events)
+	})
+	if errors.Is(err, errShipmentExists) {
+		return shipment, http.StatusConflict, errors.New("This tracking number is already registered for " + shipment.Carrier)
+	}
+	if err != nil {
+		return shipment, http.StatusInternalServerError, err
+	}
+	wakeOutboxRelay()
+	return shipment, http.StatusCreated, nil
+}
+
+// GetOrderShipments lists the shipments of one of the shopper's orders with their timelines
+func GetOrderShipments(c *gin.Context) {
+	orderObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Order ID format"})
+		return
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(c.GetString("UserID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := db.GetShipmentCollection().Find(ctx, bson.M{"orderId": orderObjectID, "userId": userObjectID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shipments", "details": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	shipments := []ordermodel.Shipment{}
+	if err := cursor.All(ctx, &shipments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode shipments", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"shipments": shipments})
+}
+
+// HandleCarrierWebhook takes status updates from carriers. Each update is
+// added to its shipment's timeline once, and the seller's group moves to
+// shipped when a parcel is on its way and to delivered once all its parcels
+// arrived.
+func HandleCarrierWebhook(c *gin.Context) {
+	secret := os.Getenv("CARRIER_WEBHOOK_SECRET")
+	if secret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Carrier webhook is not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if !validCarrierSignature(secret, body, c.GetHeader(carrierSignatureHeader)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	var update dto.CarrierUpdate
+	if err := binding.JSON.BindBody(body, &update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid carrier update", "details": err.Error()})
+		return
+	}
+	if !carrierStatuses[update.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown shipment status " + string(update.Status)})
+		return
+	}
+	update.Carrier = normalizeCarrier(update.Carrier)
+	update.TrackingNumber = strings.TrimSpace(update.TrackingNumber)
+	if update.OccurredAt.IsZero() {
+		update.OccurredAt = time.Now()
+	}
+
+	for attempt := 0; attempt < fulfilmentAttempts; attempt++ {
+		shipment, err := recordCarrierUpdate(update)
+		if errors.Is(err, errOrderChanged) || errors.Is(err, errShipmentChanged) {
+			continue
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No shipment with this carrier and tracking number"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record carrier update", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "Shipment updated successfully",
+			"shipmentId": shipment.ID,
+			"status":     shipment.Status,
+		})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "Shipment changed while it was being updated, please retry"})
+}
+
+// recordCarrierUpdate adds update to its shipment's timeline and moves the
+// seller's group on. A redelivered update is not recorded again, but the group
+// is still brought up to date in case the first delivery failed half way.
+func recordCarrierUpdate(update dto.CarrierUpdate) (ordermodel.Shipment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var shipment ordermodel.Shipment
+	err := db.GetShipmentCollection().FindOne(ctx, bson.M{"carrier": update.Carrier, "trackingNumber": update.TrackingNumber}).Decode(&shipment)
+	if err != nil {
+		return shipment, err
+	}
+
+	now := time.Now()
+	shipmentReadAt := shipment.UpdatedAt
+	recorded := false
+	for _, event := range shipment.Events {
+		if event.EventID == update.EventID {
+			recorded = true
+			break
+		}
+	}
+	if !recorded {
+		shipment.Events = append(shipment.Events, ordermodel.ShipmentEvent{
+			EventID:     update.EventID,
+			Status:      update.Status,
+			Description: update.Description,
+			Location:    update.Location,
+			OccurredAt:  update.OccurredAt,
+			ReceivedAt:  now,
+		})
+		// carriers don't always send updates in order
+		sort.SliceStable(shipment.Events, func(i, j int) bool {
+			return shipment.Events[i].OccurredAt.Before(shipment.Events[j].OccurredAt)
+		})
+		shipment.Status = shipment.Events[len(shipment.Events)-1].Status
+		shipment.UpdatedAt = now
+	}
+
+	var order ordermodel.Order
+	err = db.GetOrderCollection().FindOne(ctx, bson.M{"_id": shipment.OrderID}).Decode(&order)
+	if err != nil {
+		return shipment, err
+	}
+	orderReadAt := order.UpdatedAt
+
+	groupMoved := false
+	if group := sellerGroup(&order, shipment.SellerID); group != nil {
+		target, err := groupShippingStatus(ctx, shipment)
+		if err != nil {
+			return shipment, err
+		}
+		if target != "" {
+			groupMoved = advanceGroupTo(group, target, now)
+		}
+	}
+	if recorded && !groupMoved {
+		return shipment, nil
+	}
+
+	var events []ordermodel.OutboxEvent
+	if !recorded {
+		if events, err = shipmentEvents(order, shipment, false); err != nil {
+			return shipment, err
+		}
+	}
+	if groupMoved {
+		order.Status = deriveOrderStatus(order.Fulfilments, order.Status)
+		order.UpdatedAt = now
+		progress, err := orderProgressEvent(order)
+		if err != nil {
+			return shipment, err
+		}
+		events = append(events, progress)
+	}
+
+	err = db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if !recorded {
+			result, err := db.GetShipmentCollection().UpdateOne(sessCtx,
+				bson.M{"_id": shipment.ID, "updatedAt": shipmentReadAt},
+				bson.M{"$set": bson.M{
+					"events":    shipment.Events,
+					"status":    shipment.Status,
+					"updatedAt": shipment.UpdatedAt,
+				}},
+			)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errShipmentChanged
+			}
+		}
+		if groupMoved {
+			if err := writeOrderProgress(sessCtx, order, orderReadAt); err != nil {
+				return err
+			}
+		}
+		return enqueueEvents(sessCtx, events)
+	})
+	if err != nil {
+		return shipment, err
+	}
+	wakeOutboxRelay()
+	return shipment, nil
+}
+
+// groupShippingStatus is where the seller's group of shipment's order stands
+// going by all its parcels, with shipment as updated: delivered once every
+// parcel arrived, shipped once any is on its way, and empty before that
+func groupShippingStatus(ctx context.Context, shipment ordermodel.Shipment) (ordermodel.OrderStatus, error) {
+	cursor, err := db.GetShipmentCollection().Find(ctx, bson.M{"orderId": shipment.OrderID, "sellerId": shipment.SellerID})
+	if err != nil {
+		return "", err
+	}
+	defer cursor.Close(ctx)
+
+	var parcels []ordermodel.Shipment
+	if err := cursor.All(ctx, &parcels); err != nil {
+		return "", err
+	}
+
+	moving, delivered := false, true
+	for _, parcel := range parcels {
+		status := parcel.Status
+		if parcel.ID == shipment.ID {
+			status = shipment.Status
+		}
+		if status != ordermodel.ShipmentDelivered {
+			delivered = false
+		}
+		if status == ordermodel.ShipmentInTransit || status == ordermodel.ShipmentOutForDelivery || status == ordermodel.ShipmentDelivered {
+			moving = true
+		}
+	}
+	switch {
+	case len(parcels) > 0 && delivered:
+		return ordermodel.StatusDelivered, nil
+	case moving:
+		return ordermodel.StatusShipped, nil
+	}
+	return "", nil
+}
+
+// shipmentEvents tells the shopper about the shipment's latest timeline entry
+// and, when the group's tracking changed, the seller dashboard about the order
+func shipmentEvents(order ordermodel.Order, shipment ordermodel.Shipment, trackingChanged bool) ([]ordermodel.OutboxEvent, error) {
+	var events []ordermodel.OutboxEvent
+	if order.ContactEmail != "" {
+		latest := shipment.Events[len(shipment.Events)-1]
+		event, err := newOutboxEvent(order.OrderID, "ShipmentUpdated", dto.ShipmentUpdatedEvent{
+			ReceiverMail:   order.ContactEmail,
+			ShipmentID:     shipment.ID.Hex(),
+			OrderID:        order.OrderID.Hex(),
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			TrackingURL:    shipment.TrackingURL,
+			Status:         latest.Status,
+			Description:    latest.Description,
+			Location:       latest.Location,
+			OccurredAt:     latest.OccurredAt,
+		}, 0)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if trackingChanged {
+		progress, err := orderProgressEvent(order)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, progress)
+	}
+	return events, nil
+}
+
+// sellerGroup returns sellerID's group of order, or nil
+func sellerGroup(order *ordermodel.Order, sellerID string) *ordermodel.FulfilmentGroup {
+	for i := range order.Fulfilments {
+		if order.Fulfilments[i].SellerID == sellerID {
+			return &order.Fulfilments[i]
+		}
+	}
+	return nil
+}
+
+// normalizeCarrier makes "UPS" and " ups" the same carrier
+func normalizeCarrier(carrier string) string {
+	return strings.ToLower(strings.TrimSpace(carrier))
+}
+
+// isWebURL accepts absolute http and https links only
+func isWebURL(raw string) bool {
+	link, err := url.Parse(raw)
+	return err == nil && (link.Scheme == "http" || link.Scheme == "https") && link.Host != "" && link.User == nil
+}
+
+// validCarrierSignature checks the webhook body against its HMAC signature
+func validCarrierSignature(secret string, body []byte, signature string) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	given, err := hex.DecodeString(signature)
+	if err != nil || len(given) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(given, mac.Sum(nil))
+}
@@ -1,9 +1,34 @@
 package db
 
-import "go.mongodb.org/mongo-driver/mongo"
+import (
+	"supernova/shared/idempotency"
 
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var mongoClient *mongo.Client
 var orderCollection *mongo.Collection
+var outboxCollection *mongo.Collection
+var sagaCollection *mongo.Collection
+var shipmentCollection *mongo.Collection
+var idempotencyStore *idempotency.MongoStore
 
 func GetOrderCollection() *mongo.Collection {
 	return orderCollection
-}
\ No newline at end of file
+}
+
+func GetOutboxCollection() *mongo.Collection {
+	return outboxCollection
+}
+
+func GetSagaCollection() *mongo.Collection {
+	return sagaCollection
+}
+
+func GetShipmentCollection() *mongo.Collection {
+	return shipmentCollection
+}
+
+func GetIdempotencyStore() *idempotency.MongoStore {
+	return idempotencyStore
+}
@@ -6,10 +6,16 @@ import (
 	"os"
 	"time"
 
+	"supernova/shared/idempotency"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// publishedOutboxRetention is how long published outbox rows are kept for debugging
+const publishedOutboxRetention = 7 * 24 * time.Hour
+
 func InitDB() {
 	uri := os.Getenv("MONGO_URI")
 
@@ -26,5 +32,71 @@ func InitDB() {
 	}
 	log.Println("✅ Order Service Connected to MongoDB!")
 
-	orderCollection = client.Database("SupernovaOrderDB").Collection("orders")
-}
\ No newline at end of file
+	mongoClient = client
+	database := client.Database("SupernovaOrderDB")
+	orderCollection = database.Collection("orders")
+	outboxCollection = database.Collection("outbox")
+	sagaCollection = database.Collection("sagas")
+	shipmentCollection = database.Collection("shipments")
+	idempotencyStore = idempotency.NewMongoStore(database.Collection("idempotencyKeys"))
+
+	createIndexes(ctx)
+}
+
+// WithTransaction runs fn in a transaction, so the order and the outbox rows
+// announcing it are committed together. Transactions need MONGO_URI to point
+// at a replica set.
+func WithTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) error) error {
+	session, err := mongoClient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+// createIndexes lets the relay find due outbox rows in order, drops old
+// published ones, lets the recovery sweep find stuck sagas, lets carrier
+// updates find their shipment and expires idempotency keys
+func createIndexes(ctx context.Context) {
+	_, err := outboxCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "availableAt", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "publishedAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(publishedOutboxRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		log.Printf("⚠️ Order Service failed to create outbox indexes: %v", err)
+	}
+
+	_, err = sagaCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "updatedAt", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("⚠️ Order Service failed to create saga indexes: %v", err)
+	}
+
+	_, err = shipmentCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "carrier", Value: 1}, {Key: "trackingNumber", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "orderId", Value: 1}},
+		},
+	})
+	if err != nil {
+		log.Printf("⚠️ Order Service failed to create shipment indexes: %v", err)
+	}
+
+	if err := idempotencyStore.EnsureIndexes(ctx); err != nil {
+		log.Printf("⚠️ Order Service failed to create idempotency indexes: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	ordermodel "supernova/orderService/order/src/orderModel"
+	"supernova/shared/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -24,6 +25,7 @@ import (
 type Cart struct {
 	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
 	Items     []ordermodel.Item             `bson:"items" json:"items"`
+	CouponCode string            `bson:"couponCode" json:"couponCode"`
 	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
@@ -39,6 +41,13 @@ type ResponseCart struct {
 type OrderData struct {
 	ReceiverMail string
 	OrderID      primitive.ObjectID
-	TotalAmount  float64
-	Currency     ordermodel.Currency
+	TotalAmount  money.Money
+}
+
+// CartClearEvent is the payload of the CartClear queue. The cart service
+// clears the user's cart unless it changed after PlacedAt.
+type CartClearEvent struct {
+	OrderID  string    `json:"orderId"`
+	UserID   string    `json:"userId"`
+	PlacedAt time.Time `json:"placedAt"`
 }
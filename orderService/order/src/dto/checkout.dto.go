@@ -0,0 +1,16 @@
+package dto
+
+// AddressChoice picks an address for an order: either an entry of the user's
+// address book by ID, or a full address given inline
+type AddressChoice struct {
+	AddressID string   `json:"addressId"`
+	Address   *Address `json:"address"`
+}
+
+// CreateOrderRequest is the optional body of POST /create. Without a shipping
+// choice the first address in the book is used; without a billing choice the
+// order is billed to the shipping address.
+type CreateOrderRequest struct {
+	ShippingAddress *AddressChoice `json:"shippingAddress"`
+	BillingAddress  *AddressChoice `json:"billingAddress"`
+}
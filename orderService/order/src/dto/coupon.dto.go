@@ -0,0 +1,33 @@
+package dto
+
+import "supernova/shared/money"
+
+// CouponRedeemRequest asks the cart service to redeem the cart's coupon for an order
+type CouponRedeemRequest struct {
+	OrderID string `json:"orderId"`
+}
+
+// CouponLineDiscount is the coupon's discount on one line, in the line's currency
+type CouponLineDiscount struct {
+	ProductID string      `json:"productId"`
+	Discount  money.Money `json:"discount"`
+}
+
+// CouponDiscount is the cart service's breakdown of a redeemed coupon
+type CouponDiscount struct {
+	Code     string               `json:"code"`
+	Discount money.Money          `json:"discount"`
+	Lines    []CouponLineDiscount `json:"lines"`
+}
+
+// CouponRedeemResponse is the cart service's reply to a redemption
+type CouponRedeemResponse struct {
+	Discount CouponDiscount `json:"discount"`
+	Error    string         `json:"error"`
+}
+
+// CouponReleaseEvent is the payload of the CouponRelease queue
+type CouponReleaseEvent struct {
+	OrderID string `json:"orderId"`
+	Reason  string `json:"reason,omitempty"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+// ExchangeRates is the product service's rate table: units of each currency per one unit of Base
+type ExchangeRates struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"supernova/orderService/order/src/orderModel"
+	"time"
+)
+
+// FulfilmentUpdate moves a seller's group of an order on. Tracking is kept
+// when the group ships.
+type FulfilmentUpdate struct {
+	Status   ordermodel.OrderStatus `json:"status" binding:"required"`
+	Tracking *ordermodel.Tracking   `json:"tracking"`
+}
+
+// OrderStatusEvent is the payload of the OrderStatusDashboard queue, sent
+// whenever an order's status or one of its groups changes
+type OrderStatusEvent struct {
+	OrderID     string                       `json:"orderId"`
+	Status      ordermodel.OrderStatus       `json:"status"`
+	Fulfilments []ordermodel.FulfilmentGroup `json:"fulfilments"`
+	UpdatedAt   time.Time                    `json:"updatedAt"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// ReserveItem is one product line sent to the product service for reservation
+type ReserveItem struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+}
+
+// ReserveRequest asks the product service to hold stock for an order
+type ReserveRequest struct {
+	OrderID string        `json:"orderId"`
+	Items   []ReserveItem `json:"items"`
+}
+
+// ReserveErrorResponse is the product service's reply when stock can't be held
+type ReserveErrorResponse struct {
+	Error     string `json:"error"`
+	ProductID string `json:"productId"`
+}
+
+// InventoryEvent is the payload of the InventoryCommit and InventoryRelease queues
+type InventoryEvent struct {
+	OrderID string `json:"orderId"`
+	Reason  string `json:"reason,omitempty"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"supernova/shared/money"
+	"time"
+)
+
+// QuoteItem is one cart line sent to the product service for pricing
+type QuoteItem struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+}
+
+// QuoteRequest asks the product service for the prices valid right now
+type QuoteRequest struct {
+	Items []QuoteItem `json:"items"`
+}
+
+// QuoteLine is the product service's price for one product, sales applied
+type QuoteLine struct {
+	ProductID     string      `json:"productId"`
+	Title         string      `json:"title"`
+	Quantity      int         `json:"quantity"`
+	Stock         int         `json:"stock"`
+	SellerID      string      `json:"sellerId"`
+	OriginalPrice money.Money `json:"originalPrice"`
+	UnitPrice     money.Money `json:"unitPrice"`
+	SaleID        string      `json:"saleId,omitempty"`
+}
+
+// QuoteResponse prices every found product; unknown IDs are listed in Missing
+type QuoteResponse struct {
+	Items    []QuoteLine `json:"items"`
+	Missing  []string    `json:"missing"`
+	QuotedAt time.Time   `json:"quotedAt"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"supernova/orderService/order/src/orderModel"
+	"time"
+)
+
+// CreateShipmentRequest is a seller's new parcel for their group of an order
+type CreateShipmentRequest struct {
+	Carrier        string `json:"carrier" binding:"required"`
+	TrackingNumber string `json:"trackingNumber" binding:"required"`
+	TrackingURL    string `json:"trackingUrl" binding:"omitempty,url"`
+}
+
+// CarrierUpdate is the body of the carrier webhook. Carriers or the adapter
+// in front of them map their own statuses onto ordermodel.ShipmentStatus.
+type CarrierUpdate struct {
+	EventID        string                    `json:"eventId" binding:"required"`
+	Carrier        string                    `json:"carrier" binding:"required"`
+	TrackingNumber string                    `json:"trackingNumber" binding:"required"`
+	Status         ordermodel.ShipmentStatus `json:"status" binding:"required"`
+	Description    string                    `json:"description"`
+	Location       string                    `json:"location"`
+	OccurredAt     time.Time                 `json:"occurredAt"`
+}
+
+// ShipmentUpdatedEvent is the payload of the ShipmentUpdated queue, read by
+// emailService to tell the shopper where their parcel is
+type ShipmentUpdatedEvent struct {
+	ReceiverMail   string                    `json:"receiverMail"`
+	ShipmentID     string                    `json:"shipmentId"`
+	OrderID        string                    `json:"orderId"`
+	Carrier        string                    `json:"carrier"`
+	TrackingNumber string                    `json:"trackingNumber"`
+	TrackingURL    string                    `json:"trackingUrl,omitempty"`
+	Status         ordermodel.ShipmentStatus `json:"status"`
+	Description    string                    `json:"description,omitempty"`
+	Location       string                    `json:"location,omitempty"`
+	OccurredAt     time.Time                 `json:"occurredAt"`
+}
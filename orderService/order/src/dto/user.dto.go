@@ -1,6 +1,7 @@
 package dto
 
 type Address struct {
+    ID         string `json:"id"`
     Street     string `json:"street"`
     City       string `json:"city"`
     State      string `json:"state"`
@@ -1,6 +1,7 @@
 package ordermodel
 
 import (
+	"supernova/shared/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,10 +12,26 @@ type Order struct {
 	// Use primitive.ObjectID for the MongoDB primary key (_id)
 	OrderID         primitive.ObjectID `json:"orderId" bson:"_id" binding:"required"`
 	UserID          primitive.ObjectID `json:"userId" bson:"userId" binding:"required"`
+	// ContactEmail is where shipment updates are sent
+	ContactEmail    string             `json:"contactEmail,omitempty" bson:"contactEmail,omitempty"`
 	Items           []Item    	    	`json:"items" bson:"items" binding:"required"`
-	TotalPrice     	Price            	`json:"totalPrice" bson:"totalPrice" binding:"required"`
+	// Subtotal, Tax and Shipping are in the settlement currency; TotalPrice is
+	// Subtotal less the coupon discount plus Tax and Shipping
+	Subtotal        money.Money        `json:"subtotal" bson:"subtotal,omitempty"`
+	Tax             money.Money        `json:"tax" bson:"tax,omitempty"`
+	Shipping        money.Money        `json:"shipping" bson:"shipping,omitempty"`
+	TotalPrice     	money.Money        	`json:"totalPrice" bson:"totalPrice" binding:"required"`
+	// Coupon is the coupon redeemed for this order, if any
+	Coupon          *AppliedCoupon     `json:"coupon,omitempty" bson:"coupon,omitempty"`
+	ExchangeRates   map[money.Currency]float64 `json:"exchangeRates,omitempty" bson:"exchangeRates,omitempty"`
+	// Status is derived from Fulfilments when the order has them
 	Status          OrderStatus        `json:"status" bson:"status"`
+	// Fulfilments splits the order by seller; each seller ships their own group
+	Fulfilments     []FulfilmentGroup  `json:"fulfilments" bson:"fulfilments,omitempty"`
+	// Address is where the order ships to and BillingAddress who it is billed
+	// to, both copied from the address book or request when the order was placed
 	Address			Address    		   `json:"address" bson:"address" binding:"required"`
+	BillingAddress  Address            `json:"billingAddress" bson:"billingAddress,omitempty"`
 	CreatedAt       time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
 	UpdatedAt       time.Time          `json:"updatedAt" bson:"updatedAt" binding:"required"`
 }
@@ -22,23 +39,49 @@ type Order struct {
 // OrderItem represents a single product within an order.
 type Item struct {
 	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
-	Price     Price            `bson:"price" json:"price"`
+	SellerID  string             `bson:"sellerId,omitempty" json:"sellerId,omitempty"`
+	Price     money.Money        `bson:"price" json:"price"`
+	// SettlementPrice is Price converted into the order's settlement currency
+	SettlementPrice money.Money `bson:"settlementPrice" json:"settlementPrice"`
+	// SaleID is the sale that set Price, if any
+	SaleID    string             `bson:"saleId,omitempty" json:"saleId,omitempty"`
+	// Discount is this line's share of the coupon, in the settlement currency
+	Discount  money.Money        `bson:"discount,omitempty" json:"discount"`
+	// Tax is the tax on this line after its discount, in the settlement currency
+	Tax       money.Money        `bson:"tax,omitempty" json:"tax"`
 	Quantity  int                `bson:"quantity" json:"quantity" binding:"required,min=1"`
 }
 
-type Price struct {
-	Amount   float64 `json:"amount" bson:"amount" binding:"required"`
-	Currency Currency  `json:"currency" bson:"currency" binding:"required"`
+// FulfilmentGroup is the part of an order one seller ships. Each group moves
+// through the order statuses on its own.
+type FulfilmentGroup struct {
+	SellerID    string      `json:"sellerId" bson:"sellerId"`
+	Status      OrderStatus `json:"status" bson:"status"`
+	Tracking    *Tracking   `json:"tracking,omitempty" bson:"tracking,omitempty"`
+	ConfirmedAt *time.Time  `json:"confirmedAt,omitempty" bson:"confirmedAt,omitempty"`
+	ShippedAt   *time.Time  `json:"shippedAt,omitempty" bson:"shippedAt,omitempty"`
+	DeliveredAt *time.Time  `json:"deliveredAt,omitempty" bson:"deliveredAt,omitempty"`
+	CancelledAt *time.Time  `json:"cancelledAt,omitempty" bson:"cancelledAt,omitempty"`
+	UpdatedAt   time.Time   `json:"updatedAt" bson:"updatedAt"`
 }
 
-type Currency string
-const (
-	USD Currency = "USD"
-	INR Currency = "INR"
-)
+// Tracking is how a shopper follows a shipped group
+type Tracking struct {
+	Carrier string `json:"carrier" bson:"carrier"`
+	Number  string `json:"number" bson:"number"`
+	URL     string `json:"url,omitempty" bson:"url,omitempty"`
+}
+
+// AppliedCoupon records a coupon and the total it took off the order, in the settlement currency
+type AppliedCoupon struct {
+	Code     string      `bson:"code" json:"code"`
+	Discount money.Money `bson:"discount" json:"discount"`
+}
 
 // ShippingAddress represents the delivery location for an order.
 type Address struct {
+	// ID is the address book entry this was copied from; empty for an address given inline
+	ID          string `json:"id,omitempty" bson:"id,omitempty"`
 	Street    	string `json:"street" binding:"required"`
 	City      	string `json:"city" binding:"required"`
 	State     	string `json:"state" binding:"required"`
@@ -0,0 +1,35 @@
+package ordermodel
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxPublished OutboxStatus = "published"
+	// OutboxFailed rows ran out of attempts and need someone to look at them
+	OutboxFailed OutboxStatus = "failed"
+)
+
+// OutboxEvent is a message waiting to be published to Queue. It is written
+// in the same transaction as the change it announces, so either both are
+// saved or neither is, and the relay keeps publishing it until the broker
+// takes it.
+type OutboxEvent struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrderID   primitive.ObjectID `json:"orderId" bson:"orderId"`
+	Queue     string             `json:"queue" bson:"queue"`
+	Body      []byte             `json:"body" bson:"body"`
+	Status    OutboxStatus       `json:"status" bson:"status"`
+	Attempts  int                `json:"attempts" bson:"attempts"`
+	LastError string             `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	// AvailableAt is when the relay may next pick the row up; it moves
+	// forward while a relay holds the row and after every failed attempt
+	AvailableAt time.Time  `json:"availableAt" bson:"availableAt"`
+	CreatedAt   time.Time  `json:"createdAt" bson:"createdAt"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty" bson:"publishedAt,omitempty"`
+}
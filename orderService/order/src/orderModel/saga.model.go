@@ -0,0 +1,38 @@
+package ordermodel
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SagaStep is one step of placing an order that has to be undone if a later one fails
+type SagaStep string
+
+const (
+	StepRedeemCoupon SagaStep = "redeemCoupon"
+	StepReserveStock SagaStep = "reserveStock"
+	StepCreateOrder  SagaStep = "createOrder"
+)
+
+type SagaStatus string
+
+const (
+	SagaRunning     SagaStatus = "running"
+	SagaCompleted   SagaStatus = "completed"
+	SagaCompensated SagaStatus = "compensated"
+)
+
+// OrderSaga tracks how far placing an order got. A step is recorded before
+// it runs, so a step whose outcome is unknown, such as a timed out call, is
+// still compensated. A saga left running by a crashed request is finished
+// or compensated by the recovery sweep.
+type OrderSaga struct {
+	OrderID   primitive.ObjectID `json:"orderId" bson:"_id"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	Status    SagaStatus         `json:"status" bson:"status"`
+	Steps     []SagaStep         `json:"steps" bson:"steps"`
+	Failure   string             `json:"failure,omitempty" bson:"failure,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
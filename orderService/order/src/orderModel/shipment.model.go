@@ -0,0 +1,46 @@
+package ordermodel
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShipmentStatus is where a parcel is according to its carrier
+type ShipmentStatus string
+
+const (
+	ShipmentCreated        ShipmentStatus = "created"
+	ShipmentInTransit      ShipmentStatus = "in_transit"
+	ShipmentOutForDelivery ShipmentStatus = "out_for_delivery"
+	ShipmentDelivered      ShipmentStatus = "delivered"
+	ShipmentException      ShipmentStatus = "exception"
+)
+
+// ShipmentEvent is one entry of a shipment's timeline
+type ShipmentEvent struct {
+	// EventID is the carrier's ID for the update, so a redelivered webhook is recorded once
+	EventID     string         `json:"eventId,omitempty" bson:"eventId,omitempty"`
+	Status      ShipmentStatus `json:"status" bson:"status"`
+	Description string         `json:"description,omitempty" bson:"description,omitempty"`
+	Location    string         `json:"location,omitempty" bson:"location,omitempty"`
+	OccurredAt  time.Time      `json:"occurredAt" bson:"occurredAt"`
+	ReceivedAt  time.Time      `json:"receivedAt" bson:"receivedAt"`
+}
+
+// Shipment is a parcel a seller sent for their fulfilment group of an order.
+// A group may ship in several parcels.
+type Shipment struct {
+	ID             primitive.ObjectID `json:"shipmentId" bson:"_id"`
+	OrderID        primitive.ObjectID `json:"orderId" bson:"orderId"`
+	UserID         primitive.ObjectID `json:"userId" bson:"userId"`
+	SellerID       string             `json:"sellerId" bson:"sellerId"`
+	Carrier        string             `json:"carrier" bson:"carrier"`
+	TrackingNumber string             `json:"trackingNumber" bson:"trackingNumber"`
+	TrackingURL    string             `json:"trackingUrl,omitempty" bson:"trackingUrl,omitempty"`
+	Status         ShipmentStatus     `json:"status" bson:"status"`
+	// Events is the carrier timeline, oldest first
+	Events    []ShipmentEvent `json:"events" bson:"events"`
+	CreatedAt time.Time       `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt" bson:"updatedAt"`
+}
@@ -2,7 +2,9 @@ package routes
 
 import (
 	"supernova/orderService/order/src/controller"
+	"supernova/orderService/order/src/db"
 	"supernova/orderService/order/src/middleware"
+	"supernova/shared/idempotency"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,11 +12,22 @@ import (
 func SetupOrderRoutes(router *gin.Engine) {
 	r := router.Group("/api/order")
 
+	// carriers post status updates here, signed with CARRIER_WEBHOOK_SECRET
+	r.POST("/shipments/webhook" , controller.HandleCarrierWebhook)
+
+	// sellers move on the part of an order they sell; a group belongs to
+	// exactly one seller, so there is nothing here for other roles
+	sellerRoutes := r.Group("/seller")
+	sellerRoutes.Use(middleware.CreateRoleAuthMiddleware("seller"))
+	sellerRoutes.PATCH("/fulfilment/:id" , controller.UpdateFulfilmentStatus)
+	sellerRoutes.POST("/shipment/:id" , controller.CreateShipment)
+
 	securedRoutes := r.Use(middleware.CreateAuthMiddleware())
 
-	securedRoutes.POST("/create" , controller.CreateOrder)
+	securedRoutes.POST("/create" , idempotency.Middleware(db.GetIdempotencyStore()) , controller.CreateOrder)
 	securedRoutes.GET("/get" , controller.GetOrders)
 	securedRoutes.GET("/get/:id" , controller.GetOrderByID)
+	securedRoutes.GET("/get/:id/shipments" , controller.GetOrderShipments)
 	securedRoutes.PATCH("/cancle/:id" , controller.CancleOrderByID)
 	securedRoutes.PATCH("/update/address/:id" , controller.UpdateOrderAddress)
 	securedRoutes.PATCH("/update/status/:id" , controller.UpdateOrderStatus)
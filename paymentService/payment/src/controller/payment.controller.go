@@ -11,6 +11,7 @@ import (
 	"supernova/paymentService/payment/src/db"
 	"supernova/paymentService/payment/src/dto"
 	paymentmodel "supernova/paymentService/payment/src/paymentModel"
+	"supernova/shared/money"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,8 +23,13 @@ type JsonPayment struct{
 	ReceiverMail string 			`json:"receiverMail"`
 	PaymentID  	primitive.ObjectID `json:"paymentID"`
 	OrderID		primitive.ObjectID `json:"orderID"`
-	Amount 		float64				`json:"amount"`
-	Currency    string				`json:"currency"`
+	Amount 		money.Money			`json:"amount"`
+}
+
+// InventoryEvent tells the product service to commit or release an order's reservation
+type InventoryEvent struct {
+	OrderID string `json:"orderId"`
+	Reason  string `json:"reason,omitempty"`
 }
 
 func CreatePayment(c *gin.Context) {
@@ -116,14 +122,20 @@ func CreatePayment(c *gin.Context) {
 	payment.OrderID = orderObjectID
 	payment.CreatedAt = time.Now()
 	payment.UpdatedAt = time.Now()
-	payment.Price = paymentmodel.Price{
-		TotalAmount: userOrder.TotalPrice.Amount,
-		Currency:    paymentmodel.Currency(userOrder.TotalPrice.Currency),
-	}
+	payment.Price = userOrder.TotalPrice
 	payment.Status = paymentmodel.StatusPending
 	payment.UserID = userObjectID
 
 	result, err := paymentCollection.InsertOne(ctx, payment)
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent request for the same order got there first
+		_ = paymentCollection.FindOne(ctx, filter).Decode(&existingPayment)
+		c.JSON(http.StatusBadRequest , gin.H{
+			"error":"payment is already initiated",
+			"paymentID":existingPayment.PaymentID,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order in database", "details": err.Error()})
 		return
@@ -132,8 +144,7 @@ func CreatePayment(c *gin.Context) {
 		ReceiverMail: emailStr,
 		OrderID: payment.OrderID,
 		PaymentID: payment.PaymentID,
-		Amount : payment.Price.TotalAmount,
-		Currency: userOrder.TotalPrice.Currency,
+		Amount : payment.Price,
 	}
 	body , err := json.Marshal(Jsonpayment)
 	if err != nil {
@@ -225,8 +236,21 @@ func VerifyPayment(c *gin.Context) {
         return
     }
 
-    
-    
+    // --- Step 6: Commit or release the order's reserved stock ---
+    inventoryQueue := "InventoryRelease"
+    if newStatus == paymentmodel.StatusCompleted {
+        inventoryQueue = "InventoryCommit"
+    }
+    inventoryEvent, err := json.Marshal(InventoryEvent{
+        OrderID: existingPayment.OrderID.Hex(),
+        Reason:  "payment " + string(newStatus),
+    })
+    if err != nil {
+        log.Printf("Failed to marshal inventory event: %v", err)
+    } else if err := broker.PublishJSON(inventoryQueue, inventoryEvent); err != nil {
+        log.Print("Error in sending message to broaker" , err.Error())
+    }
+
     // --- Step 7: Respond ---
     c.JSON(http.StatusOK, gin.H{
         "message":    "Payment verification updated",
@@ -1,9 +1,18 @@
 package db
 
-import "go.mongodb.org/mongo-driver/mongo"
+import (
+	"supernova/shared/idempotency"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 var paymentCollection *mongo.Collection
+var idempotencyStore *idempotency.MongoStore
 
 func GetPaymentCollection() *mongo.Collection{
 	return paymentCollection ;
 }
+
+func GetIdempotencyStore() *idempotency.MongoStore {
+	return idempotencyStore
+}
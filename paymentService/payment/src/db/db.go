@@ -6,6 +6,9 @@ import (
 	"os"
 	"time"
 
+	"supernova/shared/idempotency"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -26,6 +29,26 @@ func InitDB() {
 
 	log.Printf("✅ Payment Service Connected to MongoDB") ;
 
-	paymentCollection = client.Database("SupernovaPaymentDB").Collection("payment")
+	database := client.Database("SupernovaPaymentDB")
+	paymentCollection = database.Collection("payment")
+	idempotencyStore = idempotency.NewMongoStore(database.Collection("idempotencyKeys"))
+
+	createIndexes(ctx)
 
-}
\ No newline at end of file
+}
+
+// createIndexes allows one payment per order, so two concurrent create calls
+// cannot both insert, and expires idempotency keys
+func createIndexes(ctx context.Context) {
+	_, err := paymentCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "orderID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("⚠️ Payment Service failed to create payment indexes: %v", err)
+	}
+
+	if err := idempotencyStore.EnsureIndexes(ctx); err != nil {
+		log.Printf("⚠️ Payment Service failed to create idempotency indexes: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"supernova/shared/money"
 	"time"
 )
 
@@ -15,7 +16,7 @@ type OrderDTO struct {
 	OrderID   string       `json:"orderId"`
 	UserID    string       `json:"userId"`
 	Items     []ItemDTO    `json:"items"`
-	TotalPrice PriceDTO    `json:"totalPrice"` // Use float64 or int, depending on how amount is stored
+	TotalPrice money.Money `json:"totalPrice"`
 	Status    string       `json:"status"`
 	Address   AddressDTO   `json:"address"`
 	CreatedAt time.Time    `json:"createdAt"`
@@ -25,16 +26,10 @@ type OrderDTO struct {
 // ItemDTO represents a single product item within the order.
 type ItemDTO struct {
 	ProductID string    `json:"productId"`
-	Price     PriceDTO  `json:"price"`
+	Price     money.Money `json:"price"`
 	Quantity  int       `json:"quantity"`
 }
 
-// PriceDTO represents the price structure for an item.
-type PriceDTO struct {
-	Amount   float64 `json:"amount"` // Use float64 for currency precision
-	Currency string  `json:"currency"`
-}
-
 // AddressDTO represents the shipping or billing address.
 type AddressDTO struct {
 	Street     string `json:"street"`
@@ -1,6 +1,7 @@
 package paymentmodel
 
 import (
+	"supernova/shared/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,21 +16,12 @@ type Payment struct {
 	// Signature 		string             	`bson:"signature" json:"signature"`
 	Status    		Status             	`bson:"status" json:"status"`
 	UserID   		primitive.ObjectID 	`bson:"userID" json:"userID"`
-	Price           Price 				`bson:"price" json:"price"`
+	Price           money.Money			`bson:"price" json:"price"`
 	CreatedAt 		time.Time			`bson:"createdAt" json:"createdAt"`
 	UpdatedAt 		time.Time	 		`bson:"updatedAt" json:"updatedAt"`
 }
 
 type Status string
-type Price struct {
-	TotalAmount   float64 `json:"toal_amount"` // Use float64 for currency precision
-	Currency Currency  `json:"currency"`
-}
-type Currency string
-const (
-	INR Currency = "INR"
-	USD Currency = "USD"
-)
 const (
 	StatusPending Status   = "pending"
 	StatusCompleted Status = "completed"
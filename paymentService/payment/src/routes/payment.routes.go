@@ -2,7 +2,9 @@ package routes
 
 import (
 	"supernova/paymentService/payment/src/controller"
+	"supernova/paymentService/payment/src/db"
 	"supernova/paymentService/payment/src/middleware"
+	"supernova/shared/idempotency"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +14,6 @@ func PaymentRoutes(router *gin.Engine){
 
 	securedRoutes := r.Use(middleware.CreateAuthMiddleware())
 
-	securedRoutes.POST("/create/:orderID" , controller.CreatePayment)
+	securedRoutes.POST("/create/:orderID" , idempotency.Middleware(db.GetIdempotencyStore()) , controller.CreatePayment)
 	securedRoutes.POST("/verify/:paymentID" , controller.VerifyPayment)
 }
\ No newline at end of file
@@ -3,9 +3,12 @@ package product
 import (
 	"log"
 	"supernova/productService/product/src/broker"
+	"supernova/productService/product/src/cache"
+	"supernova/productService/product/src/controllers"
 	"supernova/productService/product/src/db"
 	"supernova/productService/product/src/routes"
 	"supernova/productService/product/src/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -17,8 +20,20 @@ func SetupProductApp(router *gin.Engine) {
 	}
 
 	db.InItDB()
+	cache.Init()
 	services.CloudinaryInit()
 	broker.Connect()
+	broker.ConsumeQueues(map[string]broker.MessageHandler{
+		"InventoryCommit":  controllers.HandleInventoryCommit,
+		"InventoryRelease": controllers.HandleInventoryRelease,
+		"OrderRecommendation": controllers.HandleOrderForRecommendations,
+		"ProductEventsSuggest": controllers.HandleProductEventForSuggestions,
+	})
+	go controllers.StartReservationSweeper(time.Minute)
+	services.LoadExchangeRates()
+	go services.StartExchangeRateRefresher(5 * time.Minute)
+	go controllers.StartCoPurchaseBuilder(time.Hour)
+	go controllers.BackfillSuggestions()
 	
 	routes.ProductRoutes(router)
 }
@@ -151,49 +151,36 @@ func PublishJSON(queueName string, body []byte) error {
 	return nil
 }
 
-// ConsumeQueues sets up consumers for multiple queues
-// func ConsumeQueues() {
-// 	if conn == nil || channel == nil {
-// 		Connect()
-// 	}
-
-// 	for _, q := range queues {
-// 		_, err := channel.QueueDeclare(q, true, false, false, false, nil)
-// 		if err != nil {
-// 			log.Fatalf("❌ sellerDashboard Failed to declare queue %s: %v", q, err)
-// 		}
-
-// 		msgs, err := channel.Consume(q, "", false, false, false, false, nil)
-// 		if err != nil {
-// 			log.Fatalf("❌ sellerDashboard Failed to consume queue %s: %v", q, err)
-// 		}
-
-// 		go func(queue string, msgs <-chan amqp.Delivery) {
-// 			for msg := range msgs {
-// 				handleMessage(queue, msg)
-// 			}
-// 		}(q, msgs)
-// 	}
-// 	log.Println("✅ sellerdashboard Consumers started for queues:", queues)
-// }
-
-// func handleMessage(queue string, msg amqp.Delivery) {
-// 	switch queue {
-// 	case "AuthService":
-// 		var user dto.JsonUser
-// 		_ = json.Unmarshal(msg.Body, &user)
-// 		// controller.AuthEmail(user.Email, user.Name)
-// 	case "PaymentService":
-// 		var data dto.PaymentData
-// 		_ = json.Unmarshal(msg.Body, &data)
-// 		// controller.PaymentSuccessEmail(data)
-// 	case "AuthServiceDashboard":
-// 		var user models.User
-// 		_ = json.Unmarshal(msg.Body, &user)
-// 		 controller.CreateUser(user)
-// 	}
-// 	msg.Ack(false)
-// }
+// MessageHandler processes the body of a message taken from a queue.
+// The broker package can't import controllers, so handlers are passed in.
+type MessageHandler func(body []byte)
+
+// ConsumeQueues sets up a consumer for every queue in handlers
+func ConsumeQueues(handlers map[string]MessageHandler) {
+	if conn == nil || channel == nil {
+		Connect()
+	}
+
+	for q, handler := range handlers {
+		_, err := channel.QueueDeclare(q, true, false, false, false, nil)
+		if err != nil {
+			log.Fatalf("❌ productService Failed to declare queue %s: %v", q, err)
+		}
+
+		msgs, err := channel.Consume(q, "", false, false, false, false, nil)
+		if err != nil {
+			log.Fatalf("❌ productService Failed to consume queue %s: %v", q, err)
+		}
+
+		go func(queue string, handler MessageHandler, msgs <-chan amqp.Delivery) {
+			for msg := range msgs {
+				handler(msg.Body)
+				msg.Ack(false)
+			}
+		}(q, handler, msgs)
+		log.Println("✅ productService Consumer started for queue:", q)
+	}
+}
 
 // GetChannel returns current channel
 func GetChannel() *amqp.Channel {
@@ -0,0 +1,125 @@
+// Package cache is the read-through cache in front of product reads. The
+// backend is picked by CACHE_BACKEND: "lru" (default, in-process), "redis"
+// (shared between replicas) or "none". LRU replicas hear about each other's
+// invalidations over Redis pub/sub whenever REDIS_HOST is set.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Store is a byte cache; a ttl of 0 means the entry does not expire
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	Delete(ctx context.Context, keys ...string)
+}
+
+const (
+	defaultTTL  = 5 * time.Minute
+	defaultSize = 1000
+)
+
+var (
+	store Store = noopStore{}
+	ttl         = defaultTTL
+	// broadcast carries invalidations to the other replicas' LRUs; nil when
+	// the store is shared or there is no Redis to broadcast over
+	broadcast *redis.Client
+)
+
+// Init selects the backend from the environment; Redis falls back to the
+// in-process LRU when it cannot be reached so reads never depend on it
+func Init() {
+	if d, err := time.ParseDuration(os.Getenv("CACHE_TTL")); err == nil && d > 0 {
+		ttl = d
+	}
+
+	switch os.Getenv("CACHE_BACKEND") {
+	case "none":
+		store = noopStore{}
+		log.Println("ℹ️ productService cache disabled")
+		return
+	case "redis":
+		redisStore, err := newRedisStore()
+		if err == nil {
+			store = redisStore
+			log.Println("✅ productService cache using Redis")
+			return
+		}
+		log.Printf("⚠️ productService Redis cache unavailable, using LRU: %v", err)
+	}
+
+	size, err := strconv.Atoi(os.Getenv("CACHE_SIZE"))
+	if err != nil || size <= 0 {
+		size = defaultSize
+	}
+	lru := newLRUStore(size)
+	store = lru
+	log.Printf("✅ productService cache using LRU (%d entries)", size)
+
+	if os.Getenv("REDIS_HOST") == "" {
+		return
+	}
+	client, err := newRedisClient()
+	if err != nil {
+		log.Printf("⚠️ productService cache invalidations reach this replica only: %v", err)
+		return
+	}
+	broadcast = client
+	listenForInvalidations(client, lru)
+}
+
+// GetJSON decodes a cached value into v and reports whether it was found
+func GetJSON(ctx context.Context, key string, v interface{}) bool {
+	data, ok := store.Get(ctx, key)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// SetJSON caches v for the configured TTL
+func SetJSON(ctx context.Context, key string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	store.Set(ctx, key, data, ttl)
+}
+
+// Get returns a raw cached value
+func Get(ctx context.Context, key string) ([]byte, bool) {
+	return store.Get(ctx, key)
+}
+
+// Set caches a raw value for the configured TTL
+func Set(ctx context.Context, key string, value []byte) {
+	store.Set(ctx, key, value, ttl)
+}
+
+func Delete(ctx context.Context, keys ...string) {
+	store.Delete(ctx, keys...)
+}
+
+// Invalidate deletes keys on every replica: directly when the store is
+// shared, otherwise here and on the others through the broadcast
+func Invalidate(ctx context.Context, keys ...string) {
+	store.Delete(ctx, keys...)
+	if broadcast != nil {
+		publishInvalidation(ctx, broadcast, keys)
+	}
+}
+
+type noopStore struct{}
+
+func (noopStore) Get(context.Context, string) ([]byte, bool)         { return nil, false }
+func (noopStore) Set(context.Context, string, []byte, time.Duration) {}
+func (noopStore) Delete(context.Context, ...string)                  {}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisStore shares the cache between replicas; errors count as misses
+type redisStore struct {
+	client *redis.Client
+}
+
+// invalidationChannel tells every replica which keys to drop from its own LRU
+const invalidationChannel = "productService:cache:invalidate"
+
+func newRedisStore() (*redisStore, error) {
+	client, err := newRedisClient()
+	if err != nil {
+		return nil, err
+	}
+	return &redisStore{client: client}, nil
+}
+
+func newRedisClient() (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", os.Getenv("REDIS_HOST"), os.Getenv("REDIS_PORT")),
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       0,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// listenForInvalidations drops the keys other replicas invalidate from the
+// local store; go-redis resubscribes by itself after a dropped connection
+func listenForInvalidations(client *redis.Client, local Store) {
+	pubsub := client.Subscribe(context.Background(), invalidationChannel)
+	go func() {
+		for message := range pubsub.Channel() {
+			var keys []string
+			if err := json.Unmarshal([]byte(message.Payload), &keys); err != nil {
+				log.Printf("⚠️ productService ignoring bad cache invalidation: %v", err)
+				continue
+			}
+			local.Delete(context.Background(), keys...)
+		}
+	}()
+}
+
+func publishInvalidation(ctx context.Context, client *redis.Client, keys []string) {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return
+	}
+	if err := client.Publish(ctx, invalidationChannel, payload).Err(); err != nil {
+		log.Printf("⚠️ productService cache invalidation broadcast failed: %v", err)
+	}
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, bool) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("⚠️ productService cache get failed: %v", err)
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if err := s.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		log.Printf("⚠️ productService cache set failed: %v", err)
+	}
+}
+
+func (s *redisStore) Delete(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("⚠️ productService cache delete failed: %v", err)
+	}
+}
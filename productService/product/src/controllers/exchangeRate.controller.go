@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetExchangeRates returns the current rate table
+func GetExchangeRates(c *gin.Context) {
+	c.JSON(http.StatusOK, services.GetExchangeRates())
+}
+
+// UpdateExchangeRates lets an admin replace the rate table manually
+func UpdateExchangeRates(c *gin.Context) {
+	var ratesDTO dto.ExchangeRatesDTO
+	if err := c.ShouldBindJSON(&ratesDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	table, err := services.SetExchangeRates(ratesDTO.Base, ratesDTO.Rates, "manual")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exchange rates updated",
+		"rates":   table,
+	})
+}
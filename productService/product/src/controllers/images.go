@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"errors"
+	"mime/multipart"
+	"supernova/productService/product/src/models"
+	"supernova/productService/product/src/services"
+	"sync"
+)
+
+var errInvalidImageType = errors.New("Only JPEG and PNG images are allowed")
+
+// uploadImageFiles validates and uploads multipart images to Cloudinary concurrently
+func uploadImageFiles(files []*multipart.FileHeader) ([]models.Image, error) {
+	for _, fileHeader := range files {
+		contentType := fileHeader.Header.Get("Content-Type")
+		if contentType != "image/jpeg" && contentType != "image/png" {
+			return nil, errInvalidImageType
+		}
+	}
+
+	images := make([]models.Image, len(files))
+	errs := make([]error, len(files))
+
+	var wg sync.WaitGroup
+	for i, fileHeader := range files {
+		file, err := fileHeader.Open()
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, file multipart.File) {
+			defer wg.Done()
+			defer file.Close()
+
+			res, err := services.UploadImage(file)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			images[i] = models.Image{
+				URL:       res.SecureURL,
+				Thumbnail: res.SecureURL,
+				ID:        res.PublicID,
+			}
+		}(i, file)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return images, nil
+}
@@ -0,0 +1,528 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"supernova/productService/product/src/broker"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"supernova/productService/product/src/services"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	maxImportRows = 5000
+	imageURLSep   = "|"
+	attributeSep  = ";"
+)
+
+var csvHeader = []string{"title", "description", "category", "attributes", "price_amount", "price_currency", "stock", "low_stock_threshold", "images"}
+
+// importRow is a parsed row together with its 1-based line number
+// and any error found while decoding it
+type importRow struct {
+	line int
+	data dto.ImportRowDTO
+	err  error
+}
+
+// ImportProducts accepts a CSV or NDJSON catalog file and processes it as a background job
+func ImportProducts(c *gin.Context) {
+	sellerID, exists := c.Get("UserID")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get seller ID from context"})
+		return
+	}
+	sellerIDStr, ok := sellerID.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid seller ID format"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An import file is required in the 'file' field"})
+		return
+	}
+
+	format := detectImportFormat(c.PostForm("format"), fileHeader.Filename)
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format, use csv or ndjson"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file"})
+		return
+	}
+	defer file.Close()
+
+	// The multipart temp file is removed once the request ends,
+	// so rows are decoded here and only the processing runs in the background
+	var rows []importRow
+	if format == formatCSV {
+		rows, err = parseCSVRows(file)
+	} else {
+		rows, err = parseNDJSONRows(file)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Import file has no rows"})
+		return
+	}
+	if len(rows) > maxImportRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Import file exceeds %d rows", maxImportRows)})
+		return
+	}
+
+	now := time.Now()
+	job := models.ImportJob{
+		ID:        primitive.NewObjectID(),
+		SellerID:  sellerIDStr,
+		Format:    format,
+		Status:    models.ImportPending,
+		TotalRows: len(rows),
+		Errors:    []models.RowError{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := db.GetImportJobCollection().InsertOne(ctx, job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create import job"})
+		return
+	}
+
+	go runImportJob(job.ID, sellerIDStr, c.GetString("Email"), rows)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Import started",
+		"job":     job,
+	})
+}
+
+// GetImportJob returns the progress and per-row errors of an import job
+func GetImportJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import job ID"})
+		return
+	}
+	sellerID, _ := c.Get("UserID")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job models.ImportJob
+	err = db.GetImportJobCollection().FindOne(ctx, bson.M{"_id": jobID, "seller_id": sellerID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import job not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// ExportProducts streams the seller's catalog in the same format the import accepts
+func ExportProducts(c *gin.Context) {
+	format := detectImportFormat(c.DefaultQuery("format", formatCSV), "")
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format, use csv or ndjson"})
+		return
+	}
+	sellerID, _ := c.Get("UserID")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := db.GetProductCollection().Find(ctx, bson.M{"seller_id": sellerID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+
+	if format == formatCSV {
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+
+		writer := csv.NewWriter(c.Writer)
+		_ = writer.Write(csvHeader)
+		for cursor.Next(ctx) {
+			var product models.Product
+			if err := cursor.Decode(&product); err != nil {
+				log.Println("❌ productService failed to decode product for export:", err)
+				continue
+			}
+			_ = writer.Write([]string{
+				product.Title,
+				product.Description,
+				product.Category,
+				formatAttributes(product.Attributes),
+				product.Price.Decimal(),
+				string(product.Price.Currency),
+				strconv.Itoa(product.Stock),
+				strconv.Itoa(product.LowStockThreshold),
+				strings.Join(imageURLs(product.Images), imageURLSep),
+			})
+		}
+		writer.Flush()
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	for cursor.Next(ctx) {
+		var product models.Product
+		if err := cursor.Decode(&product); err != nil {
+			log.Println("❌ productService failed to decode product for export:", err)
+			continue
+		}
+		_ = encoder.Encode(dto.ImportRowDTO{
+			Title:       product.Title,
+			Description: product.Description,
+			Category:    product.Category,
+			Attributes:  product.Attributes,
+			Price: dto.ImportPriceDTO{
+				Amount:   json.Number(product.Price.Decimal()),
+				Currency: string(product.Price.Currency),
+			},
+			Images:            imageURLs(product.Images),
+			Stock:             product.Stock,
+			LowStockThreshold: product.LowStockThreshold,
+		})
+	}
+}
+
+// detectImportFormat picks the format from an explicit value or the file extension
+func detectImportFormat(format string, filename string) string {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+	switch strings.ToLower(format) {
+	case formatCSV:
+		return formatCSV
+	case formatNDJSON, "jsonl":
+		return formatNDJSON
+	}
+	return ""
+}
+
+func parseCSVRows(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"title", "price_amount", "price_currency", "stock", "images"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []importRow
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rows = append(rows, importRow{line: line, err: err})
+			continue
+		}
+
+		row := importRow{line: line}
+		row.data.Title = field(record, "title")
+		row.data.Description = field(record, "description")
+		row.data.Category = field(record, "category")
+		row.data.Price.Currency = field(record, "price_currency")
+		if images := field(record, "images"); images != "" {
+			for _, url := range strings.Split(images, imageURLSep) {
+				if url = strings.TrimSpace(url); url != "" {
+					row.data.Images = append(row.data.Images, url)
+				}
+			}
+		}
+		row.data.Price.Amount = json.Number(field(record, "price_amount"))
+		if _, err = row.data.Price.Money(); err != nil {
+			row.err = fmt.Errorf("invalid price_amount: %q", field(record, "price_amount"))
+		} else if row.data.Stock, err = strconv.Atoi(field(record, "stock")); err != nil {
+			row.err = fmt.Errorf("invalid stock: %q", field(record, "stock"))
+		} else if row.data.Attributes, err = parseAttributes(field(record, "attributes")); err != nil {
+			row.err = err
+		} else if threshold := field(record, "low_stock_threshold"); threshold != "" {
+			if row.data.LowStockThreshold, err = strconv.Atoi(threshold); err != nil {
+				row.err = fmt.Errorf("invalid low_stock_threshold: %q", threshold)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// formatAttributes writes attributes as "key=value" pairs separated by
+// attributeSep, sorted so repeated exports are identical
+func formatAttributes(attributes map[string]string) string {
+	pairs := make([]string, 0, len(attributes))
+	for key, value := range attributes {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, attributeSep)
+}
+
+// parseAttributes reads the attributes column written by formatAttributes
+func parseAttributes(column string) (map[string]string, error) {
+	if column == "" {
+		return nil, nil
+	}
+	attributes := make(map[string]string)
+	for _, pair := range strings.Split(column, attributeSep) {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			return nil, fmt.Errorf("invalid attribute: %q, use key=value", pair)
+		}
+		attributes[key] = strings.TrimSpace(value)
+	}
+	return attributes, nil
+}
+
+func parseNDJSONRows(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := importRow{line: line}
+		if err := json.Unmarshal([]byte(text), &row.data); err != nil {
+			row.err = fmt.Errorf("invalid JSON: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read NDJSON file: %v", err)
+	}
+	return rows, nil
+}
+
+// runImportJob validates and creates every row, recording progress on the job
+// document. A panic or a progress update that can't be stored fails the job
+// instead of leaving it running.
+func runImportJob(jobID primitive.ObjectID, sellerID string, sellerEmail string, rows []importRow) {
+	collection := db.GetImportJobCollection()
+	updateJob := func(update bson.M) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := collection.UpdateByID(ctx, jobID, update)
+		return err
+	}
+	failJob := func(reason string) {
+		log.Printf("❌ productService import job %s failed: %s", jobID.Hex(), reason)
+		failedAt := time.Now()
+		err := updateJob(bson.M{"$set": bson.M{
+			"status":         models.ImportFailed,
+			"failure_reason": reason,
+			"updated_at":     failedAt,
+			"completed_at":   failedAt,
+		}})
+		if err != nil {
+			log.Printf("❌ productService failed to mark import job %s failed: %v", jobID.Hex(), err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			failJob(fmt.Sprintf("import stopped unexpectedly: %v", r))
+		}
+	}()
+
+	if err := updateJob(bson.M{"$set": bson.M{"status": models.ImportRunning, "updated_at": time.Now()}}); err != nil {
+		failJob(fmt.Sprintf("failed to start import: %v", err))
+		return
+	}
+
+	for _, row := range rows {
+		err := row.err
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&row.data)
+		}
+		if err == nil {
+			err = importProduct(sellerID, sellerEmail, row.data)
+		}
+
+		update := bson.M{
+			"$inc": bson.M{"processed": 1, "succeeded": 1},
+			"$set": bson.M{"updated_at": time.Now()},
+		}
+		if err != nil {
+			update["$inc"] = bson.M{"processed": 1, "failed": 1}
+			update["$push"] = bson.M{"errors": models.RowError{Row: row.line, Error: err.Error()}}
+		}
+		if err := updateJob(update); err != nil {
+			failJob(fmt.Sprintf("failed to record progress at row %d: %v", row.line, err))
+			return
+		}
+	}
+
+	completedAt := time.Now()
+	err := updateJob(bson.M{"$set": bson.M{
+		"status":       models.ImportCompleted,
+		"updated_at":   completedAt,
+		"completed_at": completedAt,
+	}})
+	if err != nil {
+		failJob(fmt.Sprintf("failed to complete import: %v", err))
+		return
+	}
+	log.Printf("✅ productService import job %s finished (%d rows)", jobID.Hex(), len(rows))
+}
+
+// importProduct uploads the row's images and inserts the product
+func importProduct(sellerID string, sellerEmail string, row dto.ImportRowDTO) error {
+	price, err := row.Price.Money()
+	if err != nil {
+		return err
+	}
+
+	images := make([]models.Image, len(row.Images))
+	errs := make([]error, len(row.Images))
+
+	var wg sync.WaitGroup
+	for i, url := range row.Images {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			res, err := services.UploadImageFromURL(url)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to upload image %s: %v", url, err)
+				return
+			}
+			images[i] = models.Image{
+				URL:       res.SecureURL,
+				Thumbnail: res.SecureURL,
+				ID:        res.PublicID,
+			}
+		}(i, url)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	product := models.Product{
+		ID:                primitive.NewObjectID(),
+		Title:             row.Title,
+		Description:       row.Description,
+		Category:          row.Category,
+		Attributes:        row.Attributes,
+		Price:             price,
+		Images:            images,
+		Stock:             row.Stock,
+		LowStockThreshold: row.LowStockThreshold,
+		SellerID:          sellerID,
+		SellerEmail:       sellerEmail,
+		Version:           1,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := db.GetProductCollection().InsertOne(ctx, product); err != nil {
+		return fmt.Errorf("failed to create product: %v", err)
+	}
+
+	productJson, err := json.Marshal(&product)
+	if err != nil {
+		log.Printf("err: %v", err)
+		return nil
+	}
+	if err := broker.PublishJSON("ProductDashboard", productJson); err != nil {
+		log.Printf("err: %v", err)
+	}
+
+	// the seller gets the same notification as for a product created by hand
+	productDataJson, err := json.Marshal(&dto.ProductData{
+		ReceiverMail: sellerEmail,
+		ProductName:  product.Title,
+		ProductID:    product.ID,
+		Price:        product.Price,
+	})
+	if err != nil {
+		log.Printf("err: %v", err)
+	} else if err := broker.PublishJSON("ProductCreated", productDataJson); err != nil {
+		log.Printf("err: %v", err)
+	}
+	publishProductEvent(ProductCreated, product)
+	return nil
+}
+
+func imageURLs(images []models.Image) []string {
+	urls := make([]string, 0, len(images))
+	for _, image := range images {
+		urls = append(urls, image.URL)
+	}
+	return urls
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSVRows(t *testing.T) {
+	file := strings.Join([]string{
+		"title,description,category,attributes,price_amount,price_currency,stock,low_stock_threshold,images",
+		`Shirt,Cotton,apparel,"size=XL; colour=Red",19.99,USD,10,3,https://a/1.jpg|https://a/2.jpg`,
+		"Mug,,,,5,INR,0,,https://a/3.jpg",
+		"Hat,,,size,5,INR,1,,https://a/4.jpg",
+		"Cap,,,,5,INR,1,few,https://a/5.jpg",
+	}, "\n")
+
+	rows, err := parseCSVRows(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("parseCSVRows: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(rows))
+	}
+
+	shirt := rows[0]
+	if shirt.err != nil {
+		t.Fatalf("line %d: %v", shirt.line, shirt.err)
+	}
+	if shirt.data.Category != "apparel" || shirt.data.LowStockThreshold != 3 || len(shirt.data.Images) != 2 {
+		t.Errorf("shirt = %+v", shirt.data)
+	}
+	if want := map[string]string{"size": "XL", "colour": "Red"}; !reflect.DeepEqual(shirt.data.Attributes, want) {
+		t.Errorf("attributes = %v, want %v", shirt.data.Attributes, want)
+	}
+	if mug := rows[1]; mug.err != nil || mug.data.Attributes != nil || mug.data.LowStockThreshold != 0 {
+		t.Errorf("mug = %+v, %v", mug.data, mug.err)
+	}
+	if rows[2].err == nil {
+		t.Error("attribute without a value accepted")
+	}
+	if rows[3].err == nil {
+		t.Error("invalid low_stock_threshold accepted")
+	}
+}
+
+func TestAttributesRoundTrip(t *testing.T) {
+	attributes := map[string]string{"size": "XL", "colour": "Red", "fit": ""}
+	column := formatAttributes(attributes)
+	if column != "colour=Red;fit=;size=XL" {
+		t.Errorf("formatAttributes = %q", column)
+	}
+	parsed, err := parseAttributes(column)
+	if err != nil || !reflect.DeepEqual(parsed, attributes) {
+		t.Errorf("parseAttributes(%q) = %v, %v; want %v", column, parsed, err, attributes)
+	}
+}
@@ -0,0 +1,478 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultReservationTTL = 15 * time.Minute
+
+var errReservationNotActive = errors.New("reservation is not active")
+
+// insufficientStockError reports the product that could not be reserved
+type insufficientStockError struct {
+	ProductID primitive.ObjectID
+	Requested int
+}
+
+func (e *insufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %s", e.ProductID.Hex())
+}
+
+// ReserveStock holds stock for every item of an order until it is committed or released
+func ReserveStock(c *gin.Context) {
+	var req dto.ReserveRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	orderID, err := primitive.ObjectIDFromHex(req.OrderID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+	userID := c.GetString("UserID")
+
+	// Merge repeated products so each one is decremented once
+	quantities := make(map[primitive.ObjectID]int)
+	items := make([]models.ReservedItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		productID, err := primitive.ObjectIDFromHex(item.ProductID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID", "productId": item.ProductID})
+			return
+		}
+		if _, seen := quantities[productID]; !seen {
+			items = append(items, models.ReservedItem{ProductID: productID})
+		}
+		quantities[productID] += item.Quantity
+	}
+	for i := range items {
+		items[i].Quantity = quantities[items[i].ProductID]
+	}
+
+	ttl := reservationTTL()
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	reservation, err := reserveStock(orderID, userID, items, ttl)
+	if err != nil {
+		var stockErr *insufficientStockError
+		switch {
+		case errors.As(err, &stockErr):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":     "Insufficient stock",
+				"productId": stockErr.ProductID,
+				"requested": stockErr.Requested,
+			})
+		case err == errReservationNotActive:
+			c.JSON(http.StatusConflict, gin.H{"error": "Reservation expired while stock was being reserved, please retry"})
+		case mongo.IsDuplicateKeyError(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "Stock is already reserved for this order"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Stock reserved",
+		"reservation": reservation,
+	})
+}
+
+// CommitReservation turns a reservation into a permanent stock decrement
+func CommitReservation(c *gin.Context) {
+	orderID, ok := authorizedReservationOrder(c)
+	if !ok {
+		return
+	}
+	if err := commitReservation(orderID); err != nil {
+		respondReservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reservation committed"})
+}
+
+// ReleaseReservation returns reserved stock to the product
+func ReleaseReservation(c *gin.Context) {
+	orderID, ok := authorizedReservationOrder(c)
+	if !ok {
+		return
+	}
+	if err := cancelReservation(orderID, "released by request"); err != nil {
+		respondReservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reservation released"})
+}
+
+// HandleInventoryCommit consumes the InventoryCommit queue (payment succeeded)
+func HandleInventoryCommit(body []byte) {
+	var event dto.InventoryEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Println("❌ productService invalid InventoryCommit message:", err)
+		return
+	}
+	orderID, err := primitive.ObjectIDFromHex(event.OrderID)
+	if err != nil {
+		log.Println("❌ productService invalid order ID in InventoryCommit:", event.OrderID)
+		return
+	}
+	if err := commitReservation(orderID); err != nil {
+		log.Printf("⚠️ productService could not commit reservation for order %s: %v", event.OrderID, err)
+	}
+}
+
+// HandleInventoryRelease consumes the InventoryRelease queue (order cancelled or payment failed)
+func HandleInventoryRelease(body []byte) {
+	var event dto.InventoryEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Println("❌ productService invalid InventoryRelease message:", err)
+		return
+	}
+	orderID, err := primitive.ObjectIDFromHex(event.OrderID)
+	if err != nil {
+		log.Println("❌ productService invalid order ID in InventoryRelease:", event.OrderID)
+		return
+	}
+	if err := cancelReservation(orderID, event.Reason); err != nil && err != errReservationNotActive {
+		log.Printf("⚠️ productService could not release reservation for order %s: %v", event.OrderID, err)
+	}
+}
+
+// StartReservationSweeper periodically releases reservations whose TTL has
+// passed, including pending ones left behind by a crash while reserving
+func StartReservationSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		cursor, err := db.GetReservationCollection().Find(ctx, bson.M{
+			"status":     bson.M{"$in": []models.ReservationStatus{models.ReservationReserved, models.ReservationPending}},
+			"expires_at": bson.M{"$lte": time.Now()},
+		}, options.Find().SetProjection(bson.M{"order_id": 1, "status": 1}))
+		if err != nil {
+			log.Println("❌ productService reservation sweep failed:", err)
+			cancel()
+			continue
+		}
+
+		var expired []models.Reservation
+		if err := cursor.All(ctx, &expired); err != nil {
+			log.Println("❌ productService failed to decode expired reservations:", err)
+		}
+		cancel()
+
+		for _, reservation := range expired {
+			release := releaseReservation
+			if reservation.Status == models.ReservationPending {
+				release = releasePendingReservation
+			}
+			if err := release(reservation.OrderID, "expired"); err != nil && err != errReservationNotActive {
+				log.Printf("⚠️ productService could not release expired reservation for order %s: %v", reservation.OrderID.Hex(), err)
+			}
+		}
+	}
+}
+
+// reserveStock decrements stock with one conditional update per product, so
+// two checkouts racing for the last unit can never both succeed
+func reserveStock(orderID primitive.ObjectID, userID string, items []models.ReservedItem, ttl time.Duration) (*models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	reservation := models.Reservation{
+		ID:        primitive.NewObjectID(),
+		OrderID:   orderID,
+		UserID:    userID,
+		Items:     items,
+		Status:    models.ReservationPending,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// The pending document claims the order ID before any stock is touched
+	reservations := db.GetReservationCollection()
+	if _, err := reservations.InsertOne(ctx, reservation); err != nil {
+		return nil, err
+	}
+
+	// Taken flags are only set while the reservation is still pending, so the
+	// sweeper's snapshot always names exactly the stock it must give back and
+	// anything taken after it released the reservation is returned here
+	products := db.GetProductCollection()
+	for i, item := range items {
+		var product models.Product
+		err := products.FindOneAndUpdate(ctx,
+			bson.M{"_id": item.ProductID, "stock": bson.M{"$gte": item.Quantity}},
+			bson.M{"$inc": bson.M{"stock": -item.Quantity, "reserved": item.Quantity, "version": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&product)
+		if err == mongo.ErrNoDocuments {
+			err = &insufficientStockError{ProductID: item.ProductID, Requested: item.Quantity}
+		}
+		if err != nil {
+			abandonReservation(reservation.ID, items[:i])
+			return nil, err
+		}
+		publishProductEvent(ProductStockChanged, product)
+		checkStockAlerts(product, product.Stock+item.Quantity)
+
+		result, err := reservations.UpdateOne(ctx,
+			bson.M{"_id": reservation.ID, "status": models.ReservationPending},
+			bson.M{"$set": bson.M{fmt.Sprintf("items.%d.taken", i): true}},
+		)
+		if err != nil {
+			log.Printf("❌ productService failed to mark item %s of reservation %s taken: %v", item.ProductID.Hex(), reservation.ID.Hex(), err)
+			abandonReservation(reservation.ID, items[:i+1])
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			restoreStock(items[i : i+1])
+			return nil, errReservationNotActive
+		}
+		items[i].Taken = true
+	}
+
+	// if the sweeper released the reservation after the last item was marked,
+	// it has already returned every item
+	reservation.Status = models.ReservationReserved
+	result, err := reservations.UpdateOne(ctx,
+		bson.M{"_id": reservation.ID, "status": models.ReservationPending},
+		bson.M{"$set": bson.M{"status": models.ReservationReserved, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errReservationNotActive
+	}
+	return &reservation, nil
+}
+
+// abandonReservation drops a reservation that failed part way and returns the
+// stock it took, unless the sweeper got to the pending reservation first
+func abandonReservation(reservationID primitive.ObjectID, taken []models.ReservedItem) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := db.GetReservationCollection().DeleteOne(ctx, bson.M{"_id": reservationID, "status": models.ReservationPending})
+	if err != nil {
+		log.Printf("❌ productService failed to drop reservation %s: %v", reservationID.Hex(), err)
+		return
+	}
+	if result.DeletedCount == 1 {
+		restoreStock(taken)
+	}
+}
+
+// commitReservation makes the held stock permanent; only a reserved reservation can be committed
+func commitReservation(orderID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reservation models.Reservation
+	err := db.GetReservationCollection().FindOneAndUpdate(ctx,
+		bson.M{"order_id": orderID, "status": models.ReservationReserved},
+		bson.M{"$set": bson.M{"status": models.ReservationCommitted, "updated_at": time.Now()}},
+	).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return errReservationNotActive
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, item := range reservation.Items {
+		_, err := db.GetProductCollection().UpdateOne(ctx,
+			bson.M{"_id": item.ProductID},
+			bson.M{"$inc": bson.M{"reserved": -item.Quantity}},
+		)
+		if err != nil {
+			log.Printf("❌ productService failed to commit stock for product %s: %v", item.ProductID.Hex(), err)
+			continue
+		}
+		invalidateProductCache(item.ProductID)
+	}
+	log.Printf("✅ productService committed reservation for order %s", orderID.Hex())
+	return nil
+}
+
+// releaseReservation flips the status first so stock is returned exactly once
+func releaseReservation(orderID primitive.ObjectID, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reservation models.Reservation
+	err := db.GetReservationCollection().FindOneAndUpdate(ctx,
+		bson.M{"order_id": orderID, "status": models.ReservationReserved},
+		bson.M{"$set": bson.M{"status": models.ReservationReleased, "updated_at": time.Now()}},
+	).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return errReservationNotActive
+	}
+	if err != nil {
+		return err
+	}
+
+	restoreStock(reservation.Items)
+	log.Printf("ℹ️ productService released reservation for order %s (%s)", orderID.Hex(), reason)
+	return nil
+}
+
+// cancelReservation releases an order's reservation for good, returning its
+// stock even when payment already committed it. Only a cancelled order or a
+// failed payment does this; the sweeper releases reserved stock alone, since
+// an expired reservation may be committed while it is being swept.
+func cancelReservation(orderID primitive.ObjectID, reason string) error {
+	err := releaseReservation(orderID, reason)
+	if err != errReservationNotActive {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reservation models.Reservation
+	err = db.GetReservationCollection().FindOneAndUpdate(ctx,
+		bson.M{"order_id": orderID, "status": models.ReservationCommitted},
+		bson.M{"$set": bson.M{"status": models.ReservationReleased, "updated_at": time.Now()}},
+	).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return errReservationNotActive
+	}
+	if err != nil {
+		return err
+	}
+
+	// committed stock is no longer counted as reserved, so only stock goes back
+	for _, item := range reservation.Items {
+		var product models.Product
+		err := db.GetProductCollection().FindOneAndUpdate(ctx,
+			bson.M{"_id": item.ProductID},
+			bson.M{"$inc": bson.M{"stock": item.Quantity, "version": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&product)
+		if err != nil {
+			log.Printf("❌ productService failed to restock product %s: %v", item.ProductID.Hex(), err)
+			continue
+		}
+		publishProductEvent(ProductStockChanged, product)
+		checkStockAlerts(product, product.Stock-item.Quantity)
+	}
+	log.Printf("ℹ️ productService restocked committed reservation for order %s (%s)", orderID.Hex(), reason)
+	return nil
+}
+
+// releasePendingReservation gives up on a reservation that never finished and
+// returns the stock of the items it had taken
+func releasePendingReservation(orderID primitive.ObjectID, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reservation models.Reservation
+	err := db.GetReservationCollection().FindOneAndUpdate(ctx,
+		bson.M{"order_id": orderID, "status": models.ReservationPending},
+		bson.M{"$set": bson.M{"status": models.ReservationReleased, "updated_at": time.Now()}},
+	).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return errReservationNotActive
+	}
+	if err != nil {
+		return err
+	}
+
+	var taken []models.ReservedItem
+	for _, item := range reservation.Items {
+		if item.Taken {
+			taken = append(taken, item)
+		}
+	}
+	restoreStock(taken)
+	log.Printf("ℹ️ productService released unfinished reservation for order %s (%s)", orderID.Hex(), reason)
+	return nil
+}
+
+func restoreStock(items []models.ReservedItem) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, item := range items {
+		var product models.Product
+		err := db.GetProductCollection().FindOneAndUpdate(ctx,
+			bson.M{"_id": item.ProductID},
+			bson.M{"$inc": bson.M{"stock": item.Quantity, "reserved": -item.Quantity, "version": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&product)
+		if err != nil {
+			log.Printf("❌ productService failed to restore stock for product %s: %v", item.ProductID.Hex(), err)
+			continue
+		}
+		publishProductEvent(ProductStockChanged, product)
+		checkStockAlerts(product, product.Stock-item.Quantity)
+	}
+}
+
+// authorizedReservationOrder parses the order ID and checks the caller owns the reservation
+func authorizedReservationOrder(c *gin.Context) (primitive.ObjectID, bool) {
+	orderID, err := primitive.ObjectIDFromHex(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return orderID, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reservation models.Reservation
+	err = db.GetReservationCollection().FindOne(ctx, bson.M{"order_id": orderID}).Decode(&reservation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return orderID, false
+	}
+
+	if c.GetString("Role") != "admin" && reservation.UserID != c.GetString("UserID") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to change this reservation"})
+		return orderID, false
+	}
+	return orderID, true
+}
+
+func respondReservationError(c *gin.Context, err error) {
+	if err == errReservationNotActive {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reservation is not active"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+func reservationTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("INVENTORY_RESERVATION_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaultReservationTTL
+}
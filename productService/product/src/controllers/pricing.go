@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"supernova/productService/product/src/services"
+	"supernova/shared/money"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// loadActiveSales returns the sales running at `at` for the sellers of the given products
+func loadActiveSales(ctx context.Context, products []models.Product, at time.Time) ([]models.Sale, error) {
+	seen := map[string]bool{}
+	sellers := []string{}
+	for _, product := range products {
+		if !seen[product.SellerID] {
+			seen[product.SellerID] = true
+			sellers = append(sellers, product.SellerID)
+		}
+	}
+	if len(sellers) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := db.GetSaleCollection().Find(ctx, bson.M{
+		"seller_id": bson.M{"$in": sellers},
+		"starts_at": bson.M{"$lte": at},
+		"ends_at":   bson.M{"$gt": at},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sales []models.Sale
+	if err := cursor.All(ctx, &sales); err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// effectivePrice picks the applicable sale that gives the lowest price.
+// The returned sale is nil when the product sells at its own price.
+func effectivePrice(product models.Product, sales []models.Sale) (money.Money, *models.Sale) {
+	price := product.Price
+	var chosen *models.Sale
+	for i := range sales {
+		sale := &sales[i]
+		if sale.SellerID != product.SellerID {
+			continue
+		}
+		if sale.ProductID != nil && *sale.ProductID != product.ID {
+			continue
+		}
+		if !matchesVariant(product.Attributes, sale.Variant) {
+			continue
+		}
+		discounted, ok := applySale(product.Price, *sale)
+		if ok && discounted.Amount < price.Amount {
+			price, chosen = discounted, sale
+		}
+	}
+	return price, chosen
+}
+
+// matchesVariant reports whether attributes include every pair of variant;
+// a sale without a variant matches every product
+func matchesVariant(attributes, variant map[string]string) bool {
+	for key, value := range variant {
+		if !strings.EqualFold(attributes[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// applySale discounts a price, never below zero. Fixed discounts in another
+// currency are converted first and skipped if no rate is known.
+func applySale(price money.Money, sale models.Sale) (money.Money, bool) {
+	var discount money.Money
+	switch sale.Type {
+	case models.DiscountPercentage:
+		discount = price.Percent(sale.Percent)
+	case models.DiscountFixed:
+		if sale.Amount == nil {
+			return price, false
+		}
+		converted, err := services.ConvertPrice(*sale.Amount, string(price.Currency))
+		if err != nil {
+			return price, false
+		}
+		discount = converted
+	default:
+		return price, false
+	}
+
+	discounted, _ := price.Sub(discount)
+	if discounted.Amount < 0 {
+		discounted.Amount = 0
+	}
+	return discounted, true
+}
+
+// priceProducts attaches any running sale and, when a currency is asked for,
+// the effective price converted into it
+func priceProducts(ctx context.Context, products []models.Product, currency string) ([]dto.ProductResponse, error) {
+	now := time.Now()
+	sales, err := loadActiveSales(ctx, products, now)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ProductResponse, len(products))
+	for i, product := range products {
+		response[i] = dto.ProductResponse{Product: product}
+
+		price, sale := effectivePrice(product, sales)
+		if sale != nil {
+			response[i].Sale = &dto.SalePricing{
+				SaleID:        sale.ID.Hex(),
+				OriginalPrice: product.Price,
+				SalePrice:     price,
+				EndsAt:        sale.EndsAt,
+				SecondsLeft:   int64(sale.EndsAt.Sub(now).Seconds()),
+			}
+		}
+
+		if currency == "" {
+			continue
+		}
+		display, err := services.ConvertPrice(price, currency)
+		if err != nil {
+			return nil, &displayCurrencyError{err}
+		}
+		response[i].DisplayPrice = &display
+	}
+	return response, nil
+}
+
+// displayCurrencyError marks a bad `currency` query parameter
+type displayCurrencyError struct{ err error }
+
+func (e *displayCurrencyError) Error() string { return e.err.Error() }
+
+func respondPricingError(c *gin.Context, err error) {
+	var currencyErr *displayCurrencyError
+	if errors.As(err, &currencyErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
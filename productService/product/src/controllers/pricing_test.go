@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"supernova/productService/product/src/models"
+	"supernova/shared/money"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestApplySale(t *testing.T) {
+	usd := func(minor int64) *money.Money {
+		amount := money.New(minor, money.USD)
+		return &amount
+	}
+	eur := money.New(500, money.EUR)
+
+	tests := []struct {
+		name   string
+		sale   models.Sale
+		want   int64
+		wantOK bool
+	}{
+		{"percentage", models.Sale{Type: models.DiscountPercentage, Percent: 20}, 1599, true},
+		{"percentage rounds to the cent", models.Sale{Type: models.DiscountPercentage, Percent: 12.5}, 1749, true}, // 249.875 off
+		{"full percentage", models.Sale{Type: models.DiscountPercentage, Percent: 100}, 0, true},
+		{"fixed", models.Sale{Type: models.DiscountFixed, Amount: usd(500)}, 1499, true},
+		{"fixed larger than price", models.Sale{Type: models.DiscountFixed, Amount: usd(5000)}, 0, true},
+		{"fixed without amount", models.Sale{Type: models.DiscountFixed}, 1999, false},
+		{"fixed without rate", models.Sale{Type: models.DiscountFixed, Amount: &eur}, 1999, false},
+		{"unknown type", models.Sale{Type: "bogo", Percent: 50}, 1999, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := applySale(money.New(1999, money.USD), tt.sale)
+			if got.Amount != tt.want || got.Currency != money.USD || ok != tt.wantOK {
+				t.Errorf("applySale = %v, %v; want %d USD, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEffectivePrice(t *testing.T) {
+	productID := primitive.NewObjectID()
+	otherID := primitive.NewObjectID()
+	product := models.Product{
+		ID:         productID,
+		SellerID:   "seller-1",
+		Price:      money.New(10000, money.USD),
+		Attributes: map[string]string{"size": "XL", "colour": "Red"},
+	}
+	sale := func(id string, productID *primitive.ObjectID, variant map[string]string, percent float64) models.Sale {
+		objectID, _ := primitive.ObjectIDFromHex(id)
+		return models.Sale{
+			ID:        objectID,
+			SellerID:  "seller-1",
+			ProductID: productID,
+			Variant:   variant,
+			Type:      models.DiscountPercentage,
+			Percent:   percent,
+		}
+	}
+	const (
+		sellerWide = "000000000000000000000001"
+		onProduct  = "000000000000000000000002"
+		onVariant  = "000000000000000000000003"
+		elsewhere  = "000000000000000000000004"
+	)
+
+	tests := []struct {
+		name     string
+		sales    []models.Sale
+		want     int64
+		wantSale string
+	}{
+		{"no sales", nil, 10000, ""},
+		{"seller-wide", []models.Sale{sale(sellerWide, nil, nil, 10)}, 9000, sellerWide},
+		{"another seller's sale", []models.Sale{{SellerID: "seller-2", Type: models.DiscountPercentage, Percent: 50}}, 10000, ""},
+		{"another product's sale", []models.Sale{sale(elsewhere, &otherID, nil, 50)}, 10000, ""},
+		{"product beats smaller seller-wide", []models.Sale{
+			sale(sellerWide, nil, nil, 10),
+			sale(onProduct, &productID, nil, 25),
+		}, 7500, onProduct},
+		{"seller-wide beats smaller product sale", []models.Sale{
+			sale(onProduct, &productID, nil, 5),
+			sale(sellerWide, nil, nil, 10),
+		}, 9000, sellerWide},
+		{"matching variant", []models.Sale{sale(onVariant, nil, map[string]string{"size": "xl"}, 30)}, 7000, onVariant},
+		{"matching variant of the product", []models.Sale{
+			sale(onVariant, &productID, map[string]string{"size": "XL", "colour": "red"}, 30),
+		}, 7000, onVariant},
+		{"other variant", []models.Sale{sale(onVariant, nil, map[string]string{"size": "S"}, 30)}, 10000, ""},
+		{"variant needs every attribute", []models.Sale{
+			sale(onVariant, nil, map[string]string{"size": "XL", "fit": "slim"}, 30),
+		}, 10000, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, chosen := effectivePrice(product, tt.sales)
+			if price.Amount != tt.want {
+				t.Errorf("price = %v, want %d", price, tt.want)
+			}
+			gotSale := ""
+			if chosen != nil {
+				gotSale = chosen.ID.Hex()
+			}
+			if gotSale != tt.wantSale {
+				t.Errorf("sale = %q, want %q", gotSale, tt.wantSale)
+			}
+		})
+	}
+}
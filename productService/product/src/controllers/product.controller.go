@@ -8,10 +8,12 @@ import (
 	"net/http"
 	"strconv"
 	"supernova/productService/product/src/broker"
+	"supernova/productService/product/src/cache"
 	"supernova/productService/product/src/db"
 	"supernova/productService/product/src/dto"
 	"supernova/productService/product/src/models"
 	"supernova/productService/product/src/services"
+	"supernova/shared/money"
 	"sync"
 	"time"
 
@@ -138,15 +140,18 @@ func CreateProduct(c *gin.Context) {
 
     // Create Product object
     product := models.Product{
+        ID:          primitive.NewObjectID(),
         Title:       productDTO.Title,
         Description: productDTO.Description,
-        Price: models.Price{
-            Amount:   productDTO.Price.Amount,
-            Currency: productDTO.Price.Currency,
-        },
+        Category:    productDTO.Category,
+        Attributes:  productDTO.Attributes,
+        Price:  productDTO.Price.Money(),
         Images: images,
         Stock:  productDTO.Stock,
+        LowStockThreshold: productDTO.LowStockThreshold,
         SellerID: sellerIDStr,
+        SellerEmail: userEmailStr,
+        Version: 1,
     }
 
      collection := db.GetProductCollection()
@@ -158,21 +163,21 @@ func CreateProduct(c *gin.Context) {
 	 }
      productJson ,err := json.Marshal(&product)
      if err != nil {
-        log.Print("err: %v",err.Error())
+        log.Printf("err: %v",err.Error())
      }
      broker.PublishJSON("ProductDashboard" , productJson)
      productData := dto.ProductData{
         ReceiverMail: userEmailStr ,
         ProductName: productDTO.Title,
         ProductID: result.InsertedID.(primitive.ObjectID) ,
-        Price: product.Price.Amount,
-        Currency: productDTO.Price.Currency,
+        Price: product.Price,
      }
      productDataJson ,err := json.Marshal(&productData)
      if err != nil {
-        log.Print("err: %v",err.Error())
+        log.Printf("err: %v",err.Error())
      }
      broker.PublishJSON("ProductCreated" , productDataJson)
+     publishProductEvent(ProductCreated, product)
 
     c.JSON(http.StatusOK, gin.H{
         "message": "Product created successfully",
@@ -189,6 +194,7 @@ func GetProducts(c *gin.Context) {
     maxPriceStr := c.DefaultQuery("maxprice", "")
     skipStr := c.DefaultQuery("skip", "0")
     limitStr := c.DefaultQuery("limit", "20")
+    sortBy := c.DefaultQuery("sort", "")
 
     // Convert pagination values to integers
     skip, _ := strconv.Atoi(skipStr)
@@ -197,24 +203,27 @@ func GetProducts(c *gin.Context) {
     // Build MongoDB filter
     filter := bson.M{}
 
-    // Text search (title OR description)
+    // Text search (title OR description); first pages feed the suggestions
     if q != "" {
+        if skip == 0 {
+            go logSearchQuery(c.ClientIP(), q)
+        }
         filter["$or"] = []bson.M{
             {"title": bson.M{"$regex": q, "$options": "i"}},
             {"description": bson.M{"$regex": q, "$options": "i"}},
         }
     }
 
-    // Price filtering
+    // Price filtering (bounds are decimals, stored amounts are minor units)
     priceFilter := bson.M{}
     if minPriceStr != "" {
-        if minPrice, err := strconv.ParseFloat(minPriceStr, 64); err == nil {
-            priceFilter["$gte"] = minPrice
+        if minPrice, err := money.Parse(minPriceStr, money.USD); err == nil {
+            priceFilter["$gte"] = minPrice.Amount
         }
     }
     if maxPriceStr != "" {
-        if maxPrice, err := strconv.ParseFloat(maxPriceStr, 64); err == nil {
-            priceFilter["$lte"] = maxPrice
+        if maxPrice, err := money.Parse(maxPriceStr, money.USD); err == nil {
+            priceFilter["$lte"] = maxPrice.Amount
         }
     }
     if len(priceFilter) > 0 {
@@ -230,26 +239,51 @@ func GetProducts(c *gin.Context) {
         SetSkip(int64(skip)).
         SetLimit(int64(limit))
 
-    cursor, err := db.GetProductCollection().Find(ctx, filter, findOptions)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
+    // Sorting (highest rated first, ties broken by number of reviews)
+    if sortBy == "rating" {
+        findOptions.SetSort(bson.D{
+            {Key: "rating.average", Value: -1},
+            {Key: "rating.count", Value: -1},
+        })
     }
-    defer cursor.Close(ctx)
 
-    // Decode all products
+    // Only the first pages are cached; they take most of the traffic
     var products []models.Product
-    if err := cursor.All(ctx, &products); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    cacheKey := ""
+    if skip+limit <= maxCachedListOffset {
+        cacheKey = listCacheKey(ctx, c.Request.URL.Query())
+    }
+    if cacheKey == "" || !cache.GetJSON(ctx, cacheKey, &products) {
+        cursor, err := db.GetProductCollection().Find(ctx, filter, findOptions)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        defer cursor.Close(ctx)
+
+        // Decode all products
+        if err := cursor.All(ctx, &products); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        if cacheKey != "" {
+            cache.SetJSON(ctx, cacheKey, products)
+        }
+    }
+
+    // Apply running sales and display prices in the requested currency
+    response, err := priceProducts(ctx, products, c.Query("currency"))
+    if err != nil {
+        respondPricingError(c, err)
         return
     }
 
     // Send response
-    c.JSON(http.StatusOK, gin.H{
+    respondWithETag(c, gin.H{
         "count":    len(products),
         "skip":     skip,
         "limit":    limit,
-        "products": products,
+        "products": response,
     })
     return
 }
@@ -268,17 +302,28 @@ func GetProductByID(c *gin.Context) {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
 
+    // Read through the cache; sales and currency are applied after it
     var product models.Product
-    err = db.GetProductCollection().FindOne(ctx, bson.M{"_id": objectID}).Decode(&product)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
-        } else {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    cacheKey := productCacheKey(ctx, objectID)
+    if !cache.GetJSON(ctx, cacheKey, &product) {
+        err = db.GetProductCollection().FindOne(ctx, bson.M{"_id": objectID}).Decode(&product)
+        if err != nil {
+            if err == mongo.ErrNoDocuments {
+                c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+            } else {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            }
+            return
         }
+        cache.SetJSON(ctx, cacheKey, product)
+    }
+
+    response, err := priceProducts(ctx, []models.Product{product}, c.Query("currency"))
+    if err != nil {
+        respondPricingError(c, err)
         return
     }
-    c.JSON(http.StatusOK, product)
+    respondWithETag(c, response[0])
 }
 
 func UpdateProduct(c *gin.Context) {
@@ -370,33 +415,74 @@ func UpdateProduct(c *gin.Context) {
     product := models.Product{
         Title:       productDTO.Title,
         Description: productDTO.Description,
-        Price: models.Price{
-            Amount:   productDTO.Price.Amount,
-            Currency: productDTO.Price.Currency,
-        },
+        Category:    productDTO.Category,
+        Attributes:  productDTO.Attributes,
+        Price:  productDTO.Price.Money(),
         Images: images,
+        LowStockThreshold: productDTO.LowStockThreshold,
     }   
     collection := db.GetProductCollection()
     update := bson.M{
         "$set": bson.M{
             "title":       product.Title,
             "description": product.Description,
+            "category":    product.Category,
+            "attributes":  product.Attributes,
             "price":       product.Price,
             "images":      product.Images,
+            "low_stock_threshold": product.LowStockThreshold,
         },
+        "$inc": bson.M{"version": 1},
     }
-    result, err := collection.UpdateByID(c, objectID, update)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+    err = collection.FindOneAndUpdate(c, bson.M{"_id": objectID}, update,
+        options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&product)
+    if err == mongo.ErrNoDocuments {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
         return
     }
-    if result.MatchedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
         return
     }
+    publishProductEvent(ProductUpdated, product)
+
     c.JSON(http.StatusOK, gin.H{
         "message": "Product updated successfully",
         "product": product,
     })
     return
-}
\ No newline at end of file
+}
+
+// DeleteProduct removes a product; sellers may only delete their own
+func DeleteProduct(c *gin.Context) {
+    objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+
+    filter := bson.M{"_id": objectID}
+    if c.GetString("Role") != "admin" {
+        filter["seller_id"] = c.GetString("UserID")
+    }
+
+    var product models.Product
+    err = db.GetProductCollection().FindOneAndDelete(ctx, filter).Decode(&product)
+    if err == mongo.ErrNoDocuments {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
+        return
+    }
+    publishProductDeleted(product.ID, product.Version)
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":   "Product deleted successfully",
+        "productId": product.ID,
+    })
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"supernova/productService/product/src/cache"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxCachedListOffset limits list caching to the first pages, where the
+// popular queries land; deep pagination goes straight to Mongo
+const maxCachedListOffset = 100
+
+const listGenerationKey = "products:list:gen"
+
+// Cached entries are keyed by a generation that is replaced on every change.
+// A reader takes the generation before querying Mongo, so a result read
+// just before a write is stored under the old generation and never served.
+// Generations expire with the cache TTL like any other entry.
+func cacheGeneration(ctx context.Context, key string) string {
+	if gen, ok := cache.Get(ctx, key); ok {
+		return string(gen)
+	}
+	// a missing generation (never set, invalidated or expired) must not
+	// revive old entries, so a new one is started
+	gen := strconv.FormatInt(time.Now().UnixNano(), 36)
+	cache.Set(ctx, key, []byte(gen))
+	return gen
+}
+
+func productGenerationKey(productID primitive.ObjectID) string {
+	return "product:gen:" + productID.Hex()
+}
+
+func productCacheKey(ctx context.Context, productID primitive.ObjectID) string {
+	return "product:" + productID.Hex() + ":" + cacheGeneration(ctx, productGenerationKey(productID))
+}
+
+// listCacheKey identifies a listing by its query; currency is left out
+// because prices are converted after the cache
+func listCacheKey(ctx context.Context, query url.Values) string {
+	query.Del("currency")
+	sum := sha256.Sum256([]byte(query.Encode()))
+	return "products:list:" + cacheGeneration(ctx, listGenerationKey) + ":" + hex.EncodeToString(sum[:12])
+}
+
+// invalidateProductCache drops the product's own entry and every cached
+// listing on all replicas by dropping their generations
+func invalidateProductCache(productID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	cache.Invalidate(ctx, productGenerationKey(productID), listGenerationKey)
+}
+
+// respondWithETag sends body with a content hash as ETag and answers 304
+// when the client (or nginx) already holds that version
+func respondWithETag(c *gin.Context, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"supernova/productService/product/src/broker"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Product event types
+const (
+	ProductCreated      = "ProductCreated"
+	ProductUpdated      = "ProductUpdated"
+	ProductStockChanged = "ProductStockChanged"
+	ProductDeleted      = "ProductDeleted"
+)
+
+// productEventQueues has one queue per consumer so every service sees every event
+var productEventQueues = []string{"ProductEventsDashboard", "ProductEventsCart", "ProductEventsWishlist", "ProductEventsSuggest"}
+
+// publishProductEvent fans the product's new state out to every consumer queue
+func publishProductEvent(eventType string, product models.Product) {
+	publishEvent(dto.ProductEvent{
+		Type:       eventType,
+		ProductID:  product.ID,
+		Version:    product.Version,
+		Product:    &product,
+		OccurredAt: time.Now(),
+	})
+}
+
+// publishProductDeleted announces a deletion one version past the last known state
+func publishProductDeleted(productID primitive.ObjectID, version int64) {
+	publishEvent(dto.ProductEvent{
+		Type:       ProductDeleted,
+		ProductID:  productID,
+		Version:    version + 1,
+		OccurredAt: time.Now(),
+	})
+}
+
+func publishEvent(event dto.ProductEvent) {
+	invalidateProductCache(event.ProductID)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("❌ productService failed to encode %s event: %v", event.Type, err)
+		return
+	}
+	for _, queue := range productEventQueues {
+		if err := broker.PublishJSON(queue, body); err != nil {
+			log.Printf("❌ productService failed to publish %s to %s: %v", event.Type, queue, err)
+		}
+	}
+}
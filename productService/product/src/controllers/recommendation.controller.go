@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+// recommendationLimit reads ?limit, clamped to 1..maxRecommendationLimit
+func recommendationLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRecommendationLimit)))
+	if err != nil {
+		return defaultRecommendationLimit
+	}
+	if limit < 1 {
+		return 1
+	}
+	if limit > maxRecommendationLimit {
+		return maxRecommendationLimit
+	}
+	return limit
+}
+
+// GetRelatedProducts ranks products sharing the category, then by how many
+// attributes they have in common with the given product
+func GetRelatedProducts(c *gin.Context) {
+	productID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	limit := recommendationLimit(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var product models.Product
+	err = db.GetProductCollection().FindOne(ctx, bson.M{"_id": productID}).Decode(&product)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	candidates := []bson.M{}
+	if product.Category != "" {
+		candidates = append(candidates, bson.M{"category": product.Category})
+	}
+	attributes := bson.A{}
+	for key, value := range product.Attributes {
+		candidates = append(candidates, bson.M{"attributes." + key: value})
+		attributes = append(attributes, bson.M{"k": key, "v": value})
+	}
+	if len(candidates) == 0 {
+		c.JSON(http.StatusOK, gin.H{"count": 0, "products": []dto.ProductResponse{}})
+		return
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": bson.M{"$ne": productID}, "$or": candidates}}},
+		{{Key: "$addFields", Value: bson.M{
+			"_score": bson.M{"$add": bson.A{
+				bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$category", product.Category}}, len(product.Attributes) + 1, 0}},
+				bson.M{"$size": bson.M{"$setIntersection": bson.A{
+					bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$attributes", bson.M{}}}},
+					attributes,
+				}}},
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "_score", Value: -1},
+			{Key: "rating.average", Value: -1},
+		}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_score": 0}}},
+	}
+
+	cursor, err := db.GetProductCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var products []models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := priceProducts(ctx, products, c.Query("currency"))
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(response),
+		"products": response,
+	})
+}
+
+// GetBoughtTogether lists the products most often ordered with the given one
+func GetBoughtTogether(c *gin.Context) {
+	productID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	limit := recommendationLimit(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := db.GetCoPurchaseCollection().Find(ctx, bson.M{"product_id": productID},
+		options.Find().SetSort(bson.D{{Key: "count", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var pairs []models.CoPurchase
+	if err := cursor.All(ctx, &pairs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(pairs))
+	for _, pair := range pairs {
+		ids = append(ids, pair.RelatedID)
+	}
+
+	productCursor, err := db.GetProductCollection().Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer productCursor.Close(ctx)
+
+	var found []models.Product
+	if err := productCursor.All(ctx, &found); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// keep the co-purchase order; deleted products simply drop out
+	byID := make(map[primitive.ObjectID]models.Product, len(found))
+	for _, product := range found {
+		byID[product.ID] = product
+	}
+	products := make([]models.Product, 0, len(found))
+	counts := make([]int, 0, len(found))
+	for _, pair := range pairs {
+		if product, ok := byID[pair.RelatedID]; ok {
+			products = append(products, product)
+			counts = append(counts, pair.Count)
+		}
+	}
+
+	response, err := priceProducts(ctx, products, c.Query("currency"))
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+
+	items := make([]gin.H, len(response))
+	for i := range response {
+		items[i] = gin.H{"product": response[i], "timesBoughtTogether": counts[i]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(items),
+		"products": items,
+	})
+}
+
+// HandleOrderForRecommendations records the basket of a new order from the
+// OrderRecommendation queue; the co-purchase table is rebuilt from baskets offline
+func HandleOrderForRecommendations(body []byte) {
+	var order dto.OrderDTO
+	if err := json.Unmarshal(body, &order); err != nil {
+		log.Printf("❌ productService invalid order event: %v", err)
+		return
+	}
+	orderID, err := primitive.ObjectIDFromHex(order.OrderID)
+	if err != nil {
+		log.Printf("❌ productService order event without a valid orderId: %q", order.OrderID)
+		return
+	}
+
+	seen := map[primitive.ObjectID]bool{}
+	basket := models.PurchaseBasket{OrderID: orderID, CreatedAt: time.Now()}
+	for _, item := range order.Items {
+		productID, err := primitive.ObjectIDFromHex(item.ProductID)
+		if err != nil || seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		basket.ProductIDs = append(basket.ProductIDs, productID)
+	}
+	if len(basket.ProductIDs) < 2 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// keyed by order ID, so a redelivered event doesn't count twice
+	_, err = db.GetPurchaseBasketCollection().ReplaceOne(ctx, bson.M{"_id": orderID}, basket,
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		log.Printf("❌ productService failed to store basket for order %s: %v", order.OrderID, err)
+	}
+}
+
+// StartCoPurchaseBuilder periodically recomputes the co-purchase table from all baskets
+func StartCoPurchaseBuilder(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; true; <-ticker.C {
+		if err := rebuildCoPurchases(); err != nil {
+			log.Printf("❌ productService failed to rebuild co-purchases: %v", err)
+		}
+	}
+}
+
+// rebuildCoPurchases counts every ordered pair of products sharing a basket
+// and swaps the result in with $out, so readers never see a partial table
+func rebuildCoPurchases() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"a": "$product_ids", "b": "$product_ids"}}},
+		{{Key: "$unwind", Value: "$a"}},
+		{{Key: "$unwind", Value: "$b"}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$ne": bson.A{"$a", "$b"}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"product_id": "$a", "related_id": "$b"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"product_id": "$_id.product_id",
+			"related_id": "$_id.related_id",
+			"count":      1,
+		}}},
+		{{Key: "$out", Value: db.GetCoPurchaseCollection().Name()}},
+	}
+
+	cursor, err := db.GetPurchaseBasketCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
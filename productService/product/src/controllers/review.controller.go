@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateReview lets a customer with a delivered order for the product leave a review
+func CreateReview(c *gin.Context) {
+	productID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var reviewDTO dto.ReviewDTO
+	if err := c.ShouldBind(&reviewDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := db.GetProductCollection().CountDocuments(ctx, bson.M{"_id": productID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	delivered, err := hasDeliveredOrder(c.GetString("Token"), productID)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	if !delivered {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only customers with a delivered order for this product can review it"})
+		return
+	}
+
+	var images []models.Image
+	if form, err := c.MultipartForm(); err == nil && len(form.File["images"]) > 0 {
+		images, err = uploadImageFiles(form.File["images"])
+		if err == errInvalidImageType {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	now := time.Now()
+	review := models.Review{
+		ID:        primitive.NewObjectID(),
+		ProductID: productID,
+		UserID:    c.GetString("UserID"),
+		Rating:    reviewDTO.Rating,
+		Title:     reviewDTO.Title,
+		Body:      reviewDTO.Body,
+		Images:    images,
+		Status:    models.ReviewPublished,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := db.GetReviewCollection().InsertOne(ctx, review); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this product"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		return
+	}
+
+	if err := updateProductRating(productID); err != nil {
+		log.Printf("❌ productService failed to update rating for %s: %v", productID.Hex(), err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Review created successfully",
+		"review":  review,
+	})
+}
+
+// GetReviews lists the published reviews of a product, newest first
+func GetReviews(c *gin.Context) {
+	productID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	skip, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := db.GetReviewCollection().Find(ctx, bson.M{
+		"product_id": productID,
+		"status":     models.ReviewPublished,
+	}, findOptions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	reviews := []models.Review{}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	_ = db.GetProductCollection().FindOne(ctx, bson.M{"_id": productID},
+		options.FindOne().SetProjection(bson.M{"rating": 1})).Decode(&product)
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(reviews),
+		"skip":    skip,
+		"limit":   limit,
+		"rating":  product.Rating,
+		"reviews": reviews,
+	})
+}
+
+// ReplyToReview lets the seller who owns the product answer a review
+func ReplyToReview(c *gin.Context) {
+	reviewID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var replyDTO dto.ReviewReplyDTO
+	if err := c.ShouldBindJSON(&replyDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	review, ok := findReview(ctx, c, reviewID)
+	if !ok {
+		return
+	}
+
+	sellerID := c.GetString("UserID")
+	var product models.Product
+	err = db.GetProductCollection().FindOne(ctx, bson.M{"_id": review.ProductID}).Decode(&product)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if product.SellerID != sellerID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only reply to reviews of your own products"})
+		return
+	}
+
+	reply := models.SellerReply{
+		SellerID:  sellerID,
+		Body:      replyDTO.Body,
+		CreatedAt: time.Now(),
+	}
+	_, err = db.GetReviewCollection().UpdateByID(ctx, reviewID, bson.M{
+		"$set": bson.M{"seller_reply": reply, "updated_at": time.Now()},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reply"})
+		return
+	}
+
+	review.SellerReply = &reply
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reply saved",
+		"review":  review,
+	})
+}
+
+// ModerateReview lets an admin hide or republish a review
+func ModerateReview(c *gin.Context) {
+	reviewID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var moderationDTO dto.ReviewModerationDTO
+	if err := c.ShouldBindJSON(&moderationDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	review, ok := findReview(ctx, c, reviewID)
+	if !ok {
+		return
+	}
+
+	status := models.ReviewStatus(moderationDTO.Status)
+	_, err = db.GetReviewCollection().UpdateByID(ctx, reviewID, bson.M{
+		"$set": bson.M{"status": status, "updated_at": time.Now()},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate review"})
+		return
+	}
+
+	if err := updateProductRating(review.ProductID); err != nil {
+		log.Printf("❌ productService failed to update rating for %s: %v", review.ProductID.Hex(), err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Review moderated",
+		"reviewId": reviewID,
+		"status":   status,
+	})
+}
+
+func findReview(ctx context.Context, c *gin.Context, reviewID primitive.ObjectID) (models.Review, bool) {
+	var review models.Review
+	err := db.GetReviewCollection().FindOne(ctx, bson.M{"_id": reviewID}).Decode(&review)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return review, false
+	}
+	return review, true
+}
+
+// hasDeliveredOrder asks the order service whether the caller received the
+// product, going by the fulfilment group the product shipped in
+func hasDeliveredOrder(token string, productID primitive.ObjectID) (bool, error) {
+	req, err := http.NewRequest("GET", os.Getenv("ORDER_SERVICE_URL")+"/api/order/get", nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create order request")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to order service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("order service failed with status: %d", resp.StatusCode)
+	}
+
+	var ordersResp dto.OrdersResponseDTO
+	if err := json.NewDecoder(resp.Body).Decode(&ordersResp); err != nil {
+		return false, fmt.Errorf("failed to decode orders")
+	}
+
+	// Only the seller's group holding the product counts: the order status is
+	// derived from every seller's group, so it is delivered late for buyers of
+	// multi-seller orders
+	for _, order := range ordersResp.Orders {
+		for _, item := range order.Items {
+			if item.ProductID != productID.Hex() {
+				continue
+			}
+			for _, group := range order.Fulfilments {
+				if group.SellerID == item.SellerID && group.Status == "delivered" {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// updateProductRating recomputes the average and count of published reviews on the product
+func updateProductRating(productID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"product_id": productID, "status": models.ReviewPublished}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     nil,
+			"average": bson.M{"$avg": "$rating"},
+			"count":   bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := db.GetReviewCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var summary models.RatingSummary
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&summary); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.GetProductCollection().UpdateByID(ctx, productID, bson.M{
+		"$set": bson.M{"rating": summary},
+	})
+	if err == nil {
+		invalidateProductCache(productID)
+	}
+	return err
+}
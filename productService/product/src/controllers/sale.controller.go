@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateSale schedules a discount on one of the seller's products or on all of them
+func CreateSale(c *gin.Context) {
+	var saleDTO dto.SaleDTO
+	if err := c.ShouldBindJSON(&saleDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if saleDTO.Type == string(models.DiscountPercentage) && saleDTO.Percent == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "percent is required for a percentage sale"})
+		return
+	}
+	if saleDTO.Type == string(models.DiscountFixed) && saleDTO.Amount == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount is required for a fixed sale"})
+		return
+	}
+
+	sellerID := c.GetString("UserID")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sale := models.Sale{
+		ID:        primitive.NewObjectID(),
+		SellerID:  sellerID,
+		Variant:   saleDTO.Variant,
+		Name:      saleDTO.Name,
+		Type:      models.DiscountType(saleDTO.Type),
+		StartsAt:  saleDTO.StartsAt,
+		EndsAt:    saleDTO.EndsAt,
+		CreatedAt: time.Now(),
+	}
+	if sale.Type == models.DiscountPercentage {
+		sale.Percent = saleDTO.Percent
+	} else {
+		amount := saleDTO.Amount.Money()
+		sale.Amount = &amount
+	}
+
+	if saleDTO.ProductID != "" {
+		productID, err := primitive.ObjectIDFromHex(saleDTO.ProductID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+			return
+		}
+		count, err := db.GetProductCollection().CountDocuments(ctx, bson.M{"_id": productID, "seller_id": sellerID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		sale.ProductID = &productID
+	}
+
+	if _, err := db.GetSaleCollection().InsertOne(ctx, sale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create sale"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Sale scheduled successfully",
+		"sale":    sale,
+	})
+}
+
+// GetSales lists the seller's sales; ?active=true keeps only those running now
+func GetSales(c *gin.Context) {
+	filter := bson.M{"seller_id": c.GetString("UserID")}
+	if c.Query("active") == "true" {
+		now := time.Now()
+		filter["starts_at"] = bson.M{"$lte": now}
+		filter["ends_at"] = bson.M{"$gt": now}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := db.GetSaleCollection().Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "starts_at", Value: -1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	sales := []models.Sale{}
+	if err := cursor.All(ctx, &sales); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(sales),
+		"sales": sales,
+	})
+}
+
+// DeleteSale cancels one of the seller's sales
+func DeleteSale(c *gin.Context) {
+	saleID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sale ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := db.GetSaleCollection().DeleteOne(ctx, bson.M{"_id": saleID, "seller_id": c.GetString("UserID")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete sale"})
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sale not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sale deleted successfully"})
+}
+
+// QuoteProducts prices a list of products at this moment, sales included.
+// The cart and checkout use it so an order pays whatever price is valid when it is placed.
+func QuoteProducts(c *gin.Context) {
+	var quoteDTO dto.QuoteRequestDTO
+	if err := c.ShouldBindJSON(&quoteDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(quoteDTO.Items))
+	for _, item := range quoteDTO.Items {
+		id, err := primitive.ObjectIDFromHex(item.ProductID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID: " + item.ProductID})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := db.GetProductCollection().Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var products []models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	sales, err := loadActiveSales(ctx, products, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	byID := make(map[primitive.ObjectID]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	response := dto.QuoteResponseDTO{
+		Items:    make([]dto.QuoteLine, 0, len(quoteDTO.Items)),
+		Missing:  []string{},
+		QuotedAt: now,
+	}
+	for i, item := range quoteDTO.Items {
+		product, ok := byID[ids[i]]
+		if !ok {
+			response.Missing = append(response.Missing, item.ProductID)
+			continue
+		}
+		price, sale := effectivePrice(product, sales)
+		line := dto.QuoteLine{
+			ProductID:     item.ProductID,
+			Title:         product.Title,
+			Quantity:      item.Quantity,
+			Stock:         product.Stock,
+			SellerID:      product.SellerID,
+			Category:      product.Category,
+			OriginalPrice: product.Price,
+			UnitPrice:     price,
+		}
+		if sale != nil {
+			line.SaleID = sale.ID.Hex()
+		}
+		response.Items = append(response.Items, line)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
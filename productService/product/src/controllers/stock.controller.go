@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"supernova/productService/product/src/broker"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpdateStock sets a product's stock (a restock or a correction) and its
+// low-stock threshold; sellers may only change their own products
+func UpdateStock(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	var stockDTO dto.StockDTO
+	if err := c.ShouldBindJSON(&stockDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": objectID}
+	set := bson.M{"stock": *stockDTO.Stock}
+	if stockDTO.LowStockThreshold != nil {
+		set["low_stock_threshold"] = *stockDTO.LowStockThreshold
+	}
+	if c.GetString("Role") != "admin" {
+		filter["seller_id"] = c.GetString("UserID")
+		// products created before alerts existed have no seller email yet
+		set["seller_email"] = c.GetString("Email")
+	}
+
+	var product models.Product
+	err = db.GetProductCollection().FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": set, "$inc": bson.M{"version": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock"})
+		return
+	}
+
+	previousStock := product.Stock
+	product.Stock = *stockDTO.Stock
+	product.Version++
+	if stockDTO.LowStockThreshold != nil {
+		product.LowStockThreshold = *stockDTO.LowStockThreshold
+	}
+	if email, ok := set["seller_email"].(string); ok {
+		product.SellerEmail = email
+	}
+	publishProductEvent(ProductStockChanged, product)
+	checkStockAlerts(product, previousStock)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock updated successfully",
+		"product": product,
+	})
+}
+
+// SubscribeToRestock signs the caller up for a one-off email when an
+// out-of-stock product becomes available again
+func SubscribeToRestock(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var product models.Product
+	err = db.GetProductCollection().FindOne(ctx, bson.M{"_id": objectID}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if product.Stock > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Product is in stock"})
+		return
+	}
+
+	subscription := models.StockSubscription{
+		ID:        primitive.NewObjectID(),
+		ProductID: objectID,
+		UserID:    c.GetString("UserID"),
+		Email:     c.GetString("Email"),
+		CreatedAt: time.Now(),
+	}
+	_, err = db.GetStockSubscriptionCollection().InsertOne(ctx, subscription)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusOK, gin.H{"message": "Already subscribed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to subscribe"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "You will be emailed when this product is back in stock",
+		"subscription": subscription,
+	})
+}
+
+func UnsubscribeFromRestock(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := db.GetStockSubscriptionCollection().DeleteOne(ctx, bson.M{
+		"product_id": objectID,
+		"user_id":    c.GetString("UserID"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if res.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed"})
+}
+
+// checkStockAlerts compares a product's stock before and after a change. Only
+// crossings alert, so a seller hears once per drop and subscribers once per restock.
+func checkStockAlerts(product models.Product, previousStock int) {
+	threshold := product.LowStockThreshold
+	if threshold > 0 && product.Stock < threshold && previousStock >= threshold {
+		publishLowStock(product)
+	}
+	if previousStock <= 0 && product.Stock > 0 {
+		go notifyRestockSubscribers(product)
+	}
+}
+
+func publishLowStock(product models.Product) {
+	if product.SellerEmail == "" {
+		log.Printf("⚠️ productService product %s is low on stock but has no seller email", product.ID.Hex())
+		return
+	}
+	body, err := json.Marshal(dto.LowStockData{
+		ReceiverMail: product.SellerEmail,
+		ProductID:    product.ID,
+		ProductName:  product.Title,
+		Stock:        product.Stock,
+		Threshold:    product.LowStockThreshold,
+	})
+	if err != nil {
+		log.Printf("❌ productService failed to encode LowStock: %v", err)
+		return
+	}
+	if err := broker.PublishJSON("LowStock", body); err != nil {
+		log.Printf("❌ productService failed to publish LowStock: %v", err)
+	}
+}
+
+// notifyRestockSubscribers deletes each subscription as it is notified, so
+// two restocks racing each other never email the same subscriber twice
+func notifyRestockSubscribers(product models.Product) {
+	subscriptions := db.GetStockSubscriptionCollection()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var subscription models.StockSubscription
+		err := subscriptions.FindOneAndDelete(ctx, bson.M{"product_id": product.ID}).Decode(&subscription)
+		cancel()
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			log.Printf("❌ productService failed to load restock subscriptions for %s: %v", product.ID.Hex(), err)
+			return
+		}
+
+		body, err := json.Marshal(dto.BackInStockData{
+			ReceiverMail: subscription.Email,
+			ProductID:    product.ID,
+			ProductName:  product.Title,
+			Price:        product.Price,
+		})
+		if err != nil {
+			log.Printf("❌ productService failed to encode BackInStock: %v", err)
+			continue
+		}
+		if err := broker.PublishJSON("BackInStock", body); err != nil {
+			log.Printf("❌ productService failed to publish BackInStock: %v", err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/dto"
+	"supernova/productService/product/src/models"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultSuggestLimit = 5
+	maxSuggestLimit     = 10
+	maxQueryLength      = 100
+	// suggestTimeout bounds every lookup; whatever has not answered by then is left out
+	suggestTimeout = 300 * time.Millisecond
+	// minQueryCount keeps a past search out of suggestions until several searches made it
+	minQueryCount = 3
+	// minLoggedQueryLength skips single keystrokes
+	minLoggedQueryLength = 2
+	// each client IP adds at most maxLoggedQueriesPerIP searches per window,
+	// and the same query only once, so no one shopper can make a query popular
+	searchLogWindow       = time.Minute
+	maxLoggedQueriesPerIP = 10
+)
+
+// searchLogs limits how many searches each client IP may log
+var searchLogs = newSearchLogLimiter()
+
+// searchLogLimiter counts the queries each IP logged in the current window
+type searchLogLimiter struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	logged      map[string]map[string]bool
+}
+
+func newSearchLogLimiter() *searchLogLimiter {
+	return &searchLogLimiter{logged: map[string]map[string]bool{}}
+}
+
+// allow reports whether ip may log q now and records it if so
+func (l *searchLogLimiter) allow(ip, q string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.windowStart) >= searchLogWindow {
+		l.windowStart = now
+		l.logged = map[string]map[string]bool{}
+	}
+	queries := l.logged[ip]
+	if queries == nil {
+		queries = map[string]bool{}
+		l.logged[ip] = queries
+	}
+	if queries[q] || len(queries) >= maxLoggedQueriesPerIP {
+		return false
+	}
+	queries[q] = true
+	return true
+}
+
+// GetSuggestions answers the search box as the shopper types: product titles
+// and categories starting with q, plus popular past searches that do
+func GetSuggestions(c *gin.Context) {
+	q := normalizeQuery(c.Query("q"))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSuggestLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	response := dto.SuggestResponseDTO{
+		Query:      q,
+		Products:   []dto.ProductSuggestionDTO{},
+		Categories: []dto.CategorySuggestionDTO{},
+		Queries:    []dto.QuerySuggestionDTO{},
+	}
+	if q == "" {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), suggestTimeout)
+	defer cancel()
+
+	// anchored, case-sensitive regexes on lowercased fields can use the indexes
+	prefix := bson.M{"$regex": "^" + regexp.QuoteMeta(q)}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		response.Products = suggestProducts(ctx, prefix, limit)
+	}()
+	go func() {
+		defer wg.Done()
+		response.Categories = suggestCategories(ctx, prefix, limit)
+	}()
+	go func() {
+		defer wg.Done()
+		response.Queries = suggestQueries(ctx, prefix, limit)
+	}()
+	wg.Wait()
+
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, response)
+}
+
+func suggestProducts(ctx context.Context, prefix bson.M, limit int) []dto.ProductSuggestionDTO {
+	results := []dto.ProductSuggestionDTO{}
+	cursor, err := db.GetSuggestionCollection().Find(ctx,
+		bson.M{"$or": bson.A{bson.M{"title_lower": prefix}, bson.M{"terms": prefix}}},
+		options.Find().SetProjection(bson.M{"title": 1}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		log.Printf("⚠️ productService product suggestions failed: %v", err)
+		return results
+	}
+	var suggestions []models.ProductSuggestion
+	if err := cursor.All(ctx, &suggestions); err != nil {
+		log.Printf("⚠️ productService product suggestions failed: %v", err)
+		return results
+	}
+	for _, suggestion := range suggestions {
+		results = append(results, dto.ProductSuggestionDTO{ProductID: suggestion.ProductID, Title: suggestion.Title})
+	}
+	return results
+}
+
+func suggestCategories(ctx context.Context, prefix bson.M, limit int) []dto.CategorySuggestionDTO {
+	results := []dto.CategorySuggestionDTO{}
+	cursor, err := db.GetSuggestionCollection().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"category_lower": prefix}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$category_lower",
+			"name":  bson.M{"$first": "$category"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	})
+	if err != nil {
+		log.Printf("⚠️ productService category suggestions failed: %v", err)
+		return results
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		log.Printf("⚠️ productService category suggestions failed: %v", err)
+		return []dto.CategorySuggestionDTO{}
+	}
+	return results
+}
+
+func suggestQueries(ctx context.Context, prefix bson.M, limit int) []dto.QuerySuggestionDTO {
+	results := []dto.QuerySuggestionDTO{}
+	cursor, err := db.GetSearchQueryCollection().Find(ctx,
+		bson.M{"_id": prefix, "count": bson.M{"$gte": minQueryCount}},
+		options.Find().SetSort(bson.D{{Key: "count", Value: -1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		log.Printf("⚠️ productService query suggestions failed: %v", err)
+		return results
+	}
+	var queries []models.SearchQuery
+	if err := cursor.All(ctx, &queries); err != nil {
+		log.Printf("⚠️ productService query suggestions failed: %v", err)
+		return results
+	}
+	for _, query := range queries {
+		results = append(results, dto.QuerySuggestionDTO{Query: query.Query, Count: query.Count})
+	}
+	return results
+}
+
+// logSearchQuery counts a storefront search from clientIP so popular queries
+// can be suggested
+func logSearchQuery(clientIP string, q string) {
+	q = normalizeQuery(q)
+	if len([]rune(q)) < minLoggedQueryLength || !searchLogs.allow(clientIP, q, time.Now()) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := db.GetSearchQueryCollection().UpdateOne(ctx,
+		bson.M{"_id": q},
+		bson.M{
+			"$inc": bson.M{"count": 1},
+			"$set": bson.M{"last_searched_at": time.Now()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Printf("⚠️ productService failed to log search query: %v", err)
+	}
+}
+
+// HandleProductEventForSuggestions consumes the ProductEventsSuggest queue
+func HandleProductEventForSuggestions(body []byte) {
+	var event dto.ProductEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("❌ productService invalid product event: %v", err)
+		return
+	}
+
+	switch event.Type {
+	case ProductCreated, ProductUpdated:
+		if event.Product != nil {
+			upsertSuggestion(suggestionFor(*event.Product))
+		}
+	case ProductDeleted:
+		// a blank tombstone keeps the version, so a late update cannot bring it back
+		upsertSuggestion(models.ProductSuggestion{
+			ProductID: event.ProductID.Hex(),
+			Terms:     []string{},
+			Version:   event.Version,
+		})
+	}
+}
+
+// upsertSuggestion writes the entry only over an older version; a newer
+// stored copy makes the upsert collide on _id, which marks the event stale
+func upsertSuggestion(suggestion models.ProductSuggestion) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := db.GetSuggestionCollection().ReplaceOne(ctx,
+		bson.M{"_id": suggestion.ProductID, "version": bson.M{"$lt": suggestion.Version}},
+		suggestion,
+		options.Replace().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return
+	}
+	if err != nil {
+		log.Printf("❌ productService failed to index suggestion for %s: %v", suggestion.ProductID, err)
+	}
+}
+
+// BackfillSuggestions indexes the existing catalog the first time suggestions are enabled
+func BackfillSuggestions() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	count, err := db.GetSuggestionCollection().EstimatedDocumentCount(ctx)
+	if err != nil || count > 0 {
+		return
+	}
+
+	cursor, err := db.GetProductCollection().Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"title": 1, "category": 1, "version": 1}))
+	if err != nil {
+		log.Printf("❌ productService suggestion backfill failed: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	indexed := 0
+	for cursor.Next(ctx) {
+		var product models.Product
+		if err := cursor.Decode(&product); err != nil {
+			continue
+		}
+		upsertSuggestion(suggestionFor(product))
+		indexed++
+	}
+	log.Printf("✅ productService indexed %d products for suggestions", indexed)
+}
+
+func suggestionFor(product models.Product) models.ProductSuggestion {
+	titleLower := normalizeQuery(product.Title)
+	return models.ProductSuggestion{
+		ProductID:     product.ID.Hex(),
+		Title:         product.Title,
+		TitleLower:    titleLower,
+		Terms:         titleTerms(titleLower),
+		Category:      product.Category,
+		CategoryLower: normalizeQuery(product.Category),
+		Version:       product.Version,
+	}
+}
+
+// titleTerms splits a title into distinct words so "iph" also finds "Apple iPhone"
+func titleTerms(title string) []string {
+	seen := make(map[string]bool)
+	terms := []string{}
+	for _, term := range strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// normalizeQuery lowercases and collapses whitespace so "  Red  Shoes" and
+// "red shoes" count as the same search
+func normalizeQuery(q string) string {
+	q = strings.Join(strings.Fields(strings.ToLower(q)), " ")
+	if runes := []rune(q); len(runes) > maxQueryLength {
+		q = string(runes[:maxQueryLength])
+	}
+	return q
+}
@@ -3,7 +3,57 @@ package db
 import "go.mongodb.org/mongo-driver/mongo"
 
 var productCollection *mongo.Collection
+var importJobCollection *mongo.Collection
+var reservationCollection *mongo.Collection
+var reviewCollection *mongo.Collection
+var exchangeRateCollection *mongo.Collection
+var saleCollection *mongo.Collection
+var purchaseBasketCollection *mongo.Collection
+var coPurchaseCollection *mongo.Collection
+var stockSubscriptionCollection *mongo.Collection
+var suggestionCollection *mongo.Collection
+var searchQueryCollection *mongo.Collection
 
 func GetProductCollection() *mongo.Collection {
 	return productCollection
 }
+
+func GetImportJobCollection() *mongo.Collection {
+	return importJobCollection
+}
+
+func GetReservationCollection() *mongo.Collection {
+	return reservationCollection
+}
+
+func GetReviewCollection() *mongo.Collection {
+	return reviewCollection
+}
+
+func GetExchangeRateCollection() *mongo.Collection {
+	return exchangeRateCollection
+}
+
+func GetSaleCollection() *mongo.Collection {
+	return saleCollection
+}
+
+func GetPurchaseBasketCollection() *mongo.Collection {
+	return purchaseBasketCollection
+}
+
+func GetCoPurchaseCollection() *mongo.Collection {
+	return coPurchaseCollection
+}
+
+func GetStockSubscriptionCollection() *mongo.Collection {
+	return stockSubscriptionCollection
+}
+
+func GetSuggestionCollection() *mongo.Collection {
+	return suggestionCollection
+}
+
+func GetSearchQueryCollection() *mongo.Collection {
+	return searchQueryCollection
+}
@@ -33,12 +33,52 @@ func InItDB() {
 	log.Printf("✅ Product Service Connected to MongoDB") ;
 
 	productCollection = client.Database("SupernovaProductDB").Collection("products")
+	importJobCollection = client.Database("SupernovaProductDB").Collection("importJobs")
+	reservationCollection = client.Database("SupernovaProductDB").Collection("reservations")
+	reviewCollection = client.Database("SupernovaProductDB").Collection("reviews")
+	exchangeRateCollection = client.Database("SupernovaProductDB").Collection("exchangeRates")
+	saleCollection = client.Database("SupernovaProductDB").Collection("sales")
+	purchaseBasketCollection = client.Database("SupernovaProductDB").Collection("purchaseBaskets")
+	coPurchaseCollection = client.Database("SupernovaProductDB").Collection("coPurchases")
+	stockSubscriptionCollection = client.Database("SupernovaProductDB").Collection("stockSubscriptions")
+	suggestionCollection = client.Database("SupernovaProductDB").Collection("suggestions")
+	searchQueryCollection = client.Database("SupernovaProductDB").Collection("searchQueries")
 	
 	err = CreateProductIndex(productCollection)
 	if err != nil {
 		log.Fatalf("Failed to create index: %v", err)
 	}
 
+	err = CreateReservationIndexes(reservationCollection)
+	if err != nil {
+		log.Fatalf("Failed to create reservation indexes: %v", err)
+	}
+
+	err = CreateReviewIndexes(reviewCollection)
+	if err != nil {
+		log.Fatalf("Failed to create review indexes: %v", err)
+	}
+
+	err = CreateSaleIndexes(saleCollection)
+	if err != nil {
+		log.Fatalf("Failed to create sale indexes: %v", err)
+	}
+
+	err = CreateRecommendationIndexes(productCollection, coPurchaseCollection)
+	if err != nil {
+		log.Fatalf("Failed to create recommendation indexes: %v", err)
+	}
+
+	err = CreateStockSubscriptionIndexes(stockSubscriptionCollection)
+	if err != nil {
+		log.Fatalf("Failed to create stock subscription indexes: %v", err)
+	}
+
+	err = CreateSuggestionIndexes(suggestionCollection, searchQueryCollection)
+	if err != nil {
+		log.Fatalf("Failed to create suggestion indexes: %v", err)
+	}
+
 }
 
 
@@ -55,4 +95,110 @@ func CreateProductIndex(collection *mongo.Collection) error {
 
     _, err := collection.Indexes().CreateOne(ctx, indexModel)
     return err
-}
\ No newline at end of file
+}
+
+// CreateReservationIndexes keeps one reservation per order and speeds up the expiry sweep
+func CreateReservationIndexes(collection *mongo.Collection) error {
+	ctx := context.Background()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "order_id", Value: 1}},
+			Options: options.Index().SetName("order_id_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("status_expires_at_index"),
+		},
+	})
+	return err
+}
+
+// CreateReviewIndexes allows one review per user and product and speeds up listing by product
+func CreateReviewIndexes(collection *mongo.Collection) error {
+	ctx := context.Background()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("product_user_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("product_status_created_at_index"),
+		},
+	})
+	return err
+}
+
+// CreateSaleIndexes speeds up looking up the sales running for a set of sellers
+func CreateSaleIndexes(collection *mongo.Collection) error {
+	ctx := context.Background()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "seller_id", Value: 1}, {Key: "ends_at", Value: 1}, {Key: "starts_at", Value: 1}},
+		Options: options.Index().SetName("seller_ends_at_starts_at_index"),
+	})
+	return err
+}
+
+// CreateRecommendationIndexes backs the related-products match and the
+// bought-together lookup. $out keeps the indexes of the collection it replaces.
+func CreateRecommendationIndexes(products *mongo.Collection, coPurchases *mongo.Collection) error {
+	ctx := context.Background()
+
+	_, err := products.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "category", Value: 1}},
+		Options: options.Index().SetName("category_index"),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = coPurchases.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "count", Value: -1}},
+		Options: options.Index().SetName("product_count_index"),
+	})
+	return err
+}
+
+// CreateStockSubscriptionIndexes allows one subscription per user and product
+func CreateStockSubscriptionIndexes(collection *mongo.Collection) error {
+	ctx := context.Background()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetName("product_user_unique").SetUnique(true),
+	})
+	return err
+}
+
+// CreateSuggestionIndexes backs the anchored prefix matches of /suggest.
+// Query text is the _id of searchQueries, so its default index covers it.
+func CreateSuggestionIndexes(suggestions *mongo.Collection, searchQueries *mongo.Collection) error {
+	ctx := context.Background()
+
+	_, err := suggestions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "title_lower", Value: 1}},
+			Options: options.Index().SetName("title_lower_index"),
+		},
+		{
+			Keys:    bson.D{{Key: "terms", Value: 1}},
+			Options: options.Index().SetName("terms_index"),
+		},
+		{
+			Keys:    bson.D{{Key: "category_lower", Value: 1}},
+			Options: options.Index().SetName("category_lower_index"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = searchQueries.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "count", Value: -1}},
+		Options: options.Index().SetName("count_index"),
+	})
+	return err
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"supernova/productService/product/src/models"
+	"supernova/shared/money"
+)
+
+// ExchangeRatesDTO for an admin replacing the rate table
+type ExchangeRatesDTO struct {
+	Base  string             `json:"base" binding:"required,len=3"`
+	Rates map[string]float64 `json:"rates" binding:"required,min=1,dive,keys,len=3,endkeys,gt=0"`
+}
+
+// ProductResponse is a product plus any running sale and its effective
+// price in the requested display currency
+type ProductResponse struct {
+	models.Product
+	Sale         *SalePricing `json:"sale,omitempty"`
+	DisplayPrice *money.Money `json:"displayPrice,omitempty"`
+}
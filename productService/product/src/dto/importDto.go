@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"encoding/json"
+	"supernova/shared/money"
+)
+
+// ImportPriceDTO mirrors PriceDTO for rows read from an import file.
+// Amount is a decimal string in major units so it never passes through float64.
+type ImportPriceDTO struct {
+	Amount   json.Number `json:"amount" binding:"required"`
+	Currency string      `json:"currency" binding:"required,oneof=USD INR"`
+}
+
+// Money parses the row's decimal price into minor units
+func (p ImportPriceDTO) Money() (money.Money, error) {
+	return money.Parse(p.Amount.String(), money.Currency(p.Currency))
+}
+
+// ImportRowDTO is one product row of a CSV or NDJSON catalog file.
+// It carries the same validation rules as ProductDTO, with image URLs
+// in place of uploaded files.
+type ImportRowDTO struct {
+	Title             string            `json:"title" binding:"required"`
+	Description       string            `json:"description"`
+	Category          string            `json:"category"`
+	Attributes        map[string]string `json:"attributes"`
+	Price             ImportPriceDTO    `json:"price" binding:"required"`
+	Images            []string          `json:"images" binding:"required,min=1,max=5,dive,url"`
+	Stock             int               `json:"stock" binding:"gte=0"`
+	LowStockThreshold int               `json:"low_stock_threshold,omitempty" binding:"gte=0"`
+}
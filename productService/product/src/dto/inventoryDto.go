@@ -0,0 +1,20 @@
+package dto
+
+// ReserveItemDTO is one product line to hold stock for
+type ReserveItemDTO struct {
+	ProductID string `json:"productId" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// ReserveRequestDTO asks for stock to be held for an order
+type ReserveRequestDTO struct {
+	OrderID    string           `json:"orderId" binding:"required"`
+	Items      []ReserveItemDTO `json:"items" binding:"required,min=1,dive"`
+	TTLSeconds int              `json:"ttlSeconds" binding:"omitempty,min=60,max=3600"`
+}
+
+// InventoryEvent is the payload of the InventoryCommit and InventoryRelease queues
+type InventoryEvent struct {
+	OrderID string `json:"orderId"`
+	Reason  string `json:"reason,omitempty"`
+}
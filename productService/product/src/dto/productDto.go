@@ -2,14 +2,15 @@ package dto
 
 import (
 	"mime/multipart"
+	"supernova/shared/money"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// PriceDTO for receiving price info
+// PriceDTO for receiving price info; Amount is in major units (19.99)
 type PriceDTO struct {
-    Amount   float64 `form:"amount" binding:"required"`
-    Currency string  `form:"currency" binding:"required,oneof=USD INR"`
+    Amount   float64 `form:"amount" json:"amount" binding:"required"`
+    Currency string  `form:"currency" json:"currency" binding:"required,oneof=USD INR"`
 }
 
 // ImageDTO for receiving image files
@@ -21,17 +22,24 @@ type ImageDTO struct {
 type ProductDTO struct {
     Title       string       `form:"title" binding:"required"`
     Description string       `form:"description"`
+    Category    string       `form:"category"`
+    // Attributes are sent as attributes[color]=red
+    Attributes  map[string]string `form:"attributes"`
     Price       PriceDTO     `form:"price" binding:"required"`
     Images      []*multipart.FileHeader `form:"images" binding:"required"`
     Stock       int          `form:"stock" binding:"required,gte=0"`
+    LowStockThreshold int    `form:"low_stock_threshold" binding:"gte=0"`
     // SellerID    string       `form:"seller_id" binding:"required"`
 }
 
 
 type ProductData struct {
     ReceiverMail string			`json:"receiverMail"`
-    ProductName  string			`json:"receiverMail"`
-    ProductID    primitive.ObjectID		`json:"receiverMail"`
-    Price        float64		`json:"receiverMail"`
-    Currency     string			`json:"receiverMail"`
-}
\ No newline at end of file
+    ProductName  string			`json:"productName"`
+    ProductID    primitive.ObjectID		`json:"productId"`
+    Price        money.Money		`json:"price"`
+}
+// Money converts the submitted decimal price into minor units
+func (p PriceDTO) Money() money.Money {
+	return money.FromMajor(p.Amount, money.Currency(p.Currency))
+}
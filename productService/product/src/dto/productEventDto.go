@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"supernova/productService/product/src/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductEvent is the payload of the ProductCreated, ProductUpdated,
+// ProductStockChanged and ProductDeleted events. Product is the state after
+// the change and is nil for deletions.
+type ProductEvent struct {
+	Type       string             `json:"type"`
+	ProductID  primitive.ObjectID `json:"_id"`
+	Version    int64              `json:"version"`
+	Product    *models.Product    `json:"product,omitempty"`
+	OccurredAt time.Time          `json:"occurredAt"`
+}
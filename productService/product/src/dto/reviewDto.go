@@ -0,0 +1,49 @@
+package dto
+
+import "mime/multipart"
+
+// ReviewDTO for receiving a review along with optional photos
+type ReviewDTO struct {
+	Rating int                     `form:"rating" binding:"required,min=1,max=5"`
+	Title  string                  `form:"title" binding:"required,max=120"`
+	Body   string                  `form:"body" binding:"required,max=5000"`
+	Images []*multipart.FileHeader `form:"images" binding:"omitempty,max=5"`
+}
+
+// ReviewReplyDTO for a seller's reply to a review
+type ReviewReplyDTO struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+// ReviewModerationDTO for an admin changing a review's visibility
+type ReviewModerationDTO struct {
+	Status string `json:"status" binding:"required,oneof=published hidden"`
+}
+
+// OrderItemDTO is the part of an order item the product service reads
+type OrderItemDTO struct {
+	ProductID string `json:"productId"`
+	SellerID  string `json:"sellerId"`
+}
+
+// OrderFulfilmentDTO is one seller's part of an order and how far it got
+type OrderFulfilmentDTO struct {
+	SellerID string `json:"sellerId"`
+	Status   string `json:"status"`
+}
+
+// OrderDTO is the part of an order the product service reads, both from
+// the order service API and from the OrderRecommendation queue
+type OrderDTO struct {
+	OrderID string         `json:"orderId"`
+	Status  string         `json:"status"`
+	Items   []OrderItemDTO `json:"items"`
+	// Fulfilments carry the per-seller statuses; Status is derived from them
+	Fulfilments []OrderFulfilmentDTO `json:"fulfilments"`
+}
+
+// OrdersResponseDTO is the order service's list response
+type OrdersResponseDTO struct {
+	Message string     `json:"message"`
+	Orders  []OrderDTO `json:"orders"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"supernova/shared/money"
+	"time"
+)
+
+// SaleDTO for a seller scheduling a discount. Leave ProductID empty for a
+// seller-wide sale; Variant limits it to variants with those attributes.
+// Amount is in major units and only used by fixed sales.
+type SaleDTO struct {
+	ProductID string            `json:"product_id"`
+	Variant   map[string]string `json:"variant"`
+	Name      string            `json:"name" binding:"required"`
+	Type      string            `json:"type" binding:"required,oneof=percentage fixed"`
+	Percent   float64           `json:"percent" binding:"omitempty,gt=0,lte=100"`
+	Amount    *PriceDTO         `json:"amount"`
+	StartsAt  time.Time         `json:"starts_at" binding:"required"`
+	EndsAt    time.Time         `json:"ends_at" binding:"required,gtfield=StartsAt"`
+}
+
+// SalePricing describes the sale currently applied to a product
+type SalePricing struct {
+	SaleID        string      `json:"saleId"`
+	OriginalPrice money.Money `json:"originalPrice"`
+	SalePrice     money.Money `json:"salePrice"`
+	EndsAt        time.Time   `json:"endsAt"`
+	SecondsLeft   int64       `json:"secondsLeft"`
+}
+
+// QuoteItemDTO is one line the cart or checkout wants priced
+type QuoteItemDTO struct {
+	ProductID string `json:"productId" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// QuoteRequestDTO asks for the prices valid right now
+type QuoteRequestDTO struct {
+	Items []QuoteItemDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+// QuoteLine is the price of one product at quote time
+type QuoteLine struct {
+	ProductID     string      `json:"productId"`
+	Title         string      `json:"title"`
+	Quantity      int         `json:"quantity"`
+	Stock         int         `json:"stock"`
+	SellerID      string      `json:"sellerId"`
+	Category      string      `json:"category"`
+	OriginalPrice money.Money `json:"originalPrice"`
+	UnitPrice     money.Money `json:"unitPrice"`
+	SaleID        string      `json:"saleId,omitempty"`
+}
+
+// QuoteResponseDTO prices every found product; unknown IDs are listed in Missing
+type QuoteResponseDTO struct {
+	Items    []QuoteLine `json:"items"`
+	Missing  []string    `json:"missing"`
+	QuotedAt time.Time   `json:"quotedAt"`
+}
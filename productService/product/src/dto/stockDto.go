@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"supernova/shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockDTO sets a product's stock and, optionally, its low-stock threshold
+type StockDTO struct {
+	Stock             *int `json:"stock" binding:"required,gte=0"`
+	LowStockThreshold *int `json:"low_stock_threshold" binding:"omitempty,gte=0"`
+}
+
+// LowStockData is sent to the seller when stock falls below the threshold
+type LowStockData struct {
+	ReceiverMail string             `json:"receiverMail"`
+	ProductID    primitive.ObjectID `json:"productId"`
+	ProductName  string             `json:"productName"`
+	Stock        int                `json:"stock"`
+	Threshold    int                `json:"threshold"`
+}
+
+// BackInStockData is sent to every subscriber when a product is restocked
+type BackInStockData struct {
+	ReceiverMail string             `json:"receiverMail"`
+	ProductID    primitive.ObjectID `json:"productId"`
+	ProductName  string             `json:"productName"`
+	Price        money.Money        `json:"price"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+// ProductSuggestionDTO is a product whose title matches the typed prefix
+type ProductSuggestionDTO struct {
+	ProductID string `json:"_id"`
+	Title     string `json:"title"`
+}
+
+// CategorySuggestionDTO is a matching category with its product count
+type CategorySuggestionDTO struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// QuerySuggestionDTO is a popular past search starting with the typed prefix
+type QuerySuggestionDTO struct {
+	Query string `json:"query"`
+	Count int64  `json:"count"`
+}
+
+// SuggestResponseDTO groups the suggestions shown under the search box
+type SuggestResponseDTO struct {
+	Query      string                  `json:"query"`
+	Products   []ProductSuggestionDTO  `json:"products"`
+	Categories []CategorySuggestionDTO `json:"categories"`
+	Queries    []QuerySuggestionDTO    `json:"queries"`
+}
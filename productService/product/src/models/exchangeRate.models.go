@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ExchangeRates is the rate table: units of each currency per one unit of Base
+type ExchangeRates struct {
+	ID        string             `bson:"_id" json:"-"`
+	Base      string             `bson:"base" json:"base"`
+	Rates     map[string]float64 `bson:"rates" json:"rates"`
+	Source    string             `bson:"source" json:"source"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImportStatus is the lifecycle state of a bulk import job
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// RowError reports why a single row of an import file was rejected
+type RowError struct {
+	Row   int    `bson:"row" json:"row"`
+	Error string `bson:"error" json:"error"`
+}
+
+// ImportJob tracks the progress of an asynchronous catalog import
+type ImportJob struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	SellerID  string             `bson:"seller_id" json:"seller_id"`
+	Format    string             `bson:"format" json:"format"`
+	Status    ImportStatus       `bson:"status" json:"status"`
+	TotalRows int                `bson:"total_rows" json:"total_rows"`
+	Processed int                `bson:"processed" json:"processed"`
+	Succeeded int                `bson:"succeeded" json:"succeeded"`
+	Failed    int                `bson:"failed" json:"failed"`
+	Errors    []RowError         `bson:"errors" json:"errors"`
+	// FailureReason says why a failed job stopped
+	FailureReason string     `bson:"failure_reason,omitempty" json:"failure_reason,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at" json:"updated_at"`
+	CompletedAt   *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
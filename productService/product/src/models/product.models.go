@@ -1,13 +1,10 @@
 package models
 
+import (
+    "supernova/shared/money"
 
-// import "go.mongodb.org/mongo-driver/bson/primitive"
-
-// Price sub-struct
-type Price struct {
-    Amount   float64  `bson:"amount" json:"amount" binding:"required"`
-    Currency string  `bson:"currency" json:"currency" binding:"required,oneof=USD INR"`
-}
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // Image sub-struct
 type Image struct {
@@ -18,11 +15,22 @@ type Image struct {
 
 // Product model
 type Product struct {
+    ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
     Title       string             `bson:"title" json:"title" binding:"required"`
     Description string             `bson:"description" json:"description"`
-    Price       Price              `bson:"price" json:"price"  binding:"required"`
+    Category    string             `bson:"category,omitempty" json:"category,omitempty"`
+    Attributes  map[string]string  `bson:"attributes,omitempty" json:"attributes,omitempty"`
+    Price       money.Money        `bson:"price" json:"price"  binding:"required"`
     Images      []Image            `bson:"images" json:"images" binding:"required"`
     Stock       int                `bson:"stock" json:"stock" binding:"required,gte=0"`
+    Reserved    int                `bson:"reserved" json:"reserved"`
+    Rating      RatingSummary      `bson:"rating" json:"rating"`
     SellerID    string             `bson:"seller_id" json:"seller_id" binding:"required"`
+    // SellerEmail receives low-stock alerts; kept out of API responses
+    SellerEmail string             `bson:"seller_email,omitempty" json:"-"`
+    // LowStockThreshold alerts the seller when stock drops below it; 0 turns alerts off
+    LowStockThreshold int          `bson:"low_stock_threshold,omitempty" json:"low_stock_threshold,omitempty"`
+    // Version goes up on every change so downstream copies can drop stale events
+    Version     int64              `bson:"version" json:"version"`
 }
 
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PurchaseBasket is the set of products bought in one order, kept as the raw
+// input for the co-purchase rebuild
+type PurchaseBasket struct {
+	OrderID    primitive.ObjectID   `bson:"_id" json:"orderId"`
+	ProductIDs []primitive.ObjectID `bson:"product_ids" json:"productIds"`
+	CreatedAt  time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// CoPurchase counts the orders in which two products were bought together
+type CoPurchase struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"productId"`
+	RelatedID primitive.ObjectID `bson:"related_id" json:"relatedId"`
+	Count     int                `bson:"count" json:"count"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReservationStatus is the lifecycle state of a stock reservation
+type ReservationStatus string
+
+const (
+	ReservationPending   ReservationStatus = "pending"
+	ReservationReserved  ReservationStatus = "reserved"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+)
+
+// ReservedItem is the quantity of one product held for an order
+type ReservedItem struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"productId"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+	// Taken is set once the quantity has been taken off the product's stock,
+	// so a pending reservation left by a crash returns only what it took
+	Taken bool `bson:"taken,omitempty" json:"-"`
+}
+
+// Reservation holds stock for an order until it is committed, released or expires
+type Reservation struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	OrderID   primitive.ObjectID `bson:"order_id" json:"orderId"`
+	UserID    string             `bson:"user_id" json:"userId"`
+	Items     []ReservedItem     `bson:"items" json:"items"`
+	Status    ReservationStatus  `bson:"status" json:"status"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expiresAt"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewStatus controls whether a review is shown and counted in the rating
+type ReviewStatus string
+
+const (
+	ReviewPublished ReviewStatus = "published"
+	ReviewHidden    ReviewStatus = "hidden"
+)
+
+// SellerReply is the seller's public answer to a review
+type SellerReply struct {
+	SellerID  string    `bson:"seller_id" json:"seller_id"`
+	Body      string    `bson:"body" json:"body"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// Review is a customer's rating and feedback for a product
+type Review struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	ProductID   primitive.ObjectID `bson:"product_id" json:"product_id"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	Rating      int                `bson:"rating" json:"rating"`
+	Title       string             `bson:"title" json:"title"`
+	Body        string             `bson:"body" json:"body"`
+	Images      []Image            `bson:"images" json:"images"`
+	Status      ReviewStatus       `bson:"status" json:"status"`
+	SellerReply *SellerReply       `bson:"seller_reply,omitempty" json:"seller_reply,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// RatingSummary is the aggregate of a product's published reviews
+type RatingSummary struct {
+	Average float64 `bson:"average" json:"average"`
+	Count   int     `bson:"count" json:"count"`
+}
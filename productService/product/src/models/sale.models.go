@@ -0,0 +1,34 @@
+package models
+
+import (
+	"supernova/shared/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiscountType says how a sale lowers the price
+type DiscountType string
+
+const (
+	DiscountPercentage DiscountType = "percentage"
+	DiscountFixed      DiscountType = "fixed"
+)
+
+// Sale is a scheduled discount on one product, or on every product of
+// the seller when ProductID is nil. Variant narrows either to the variants
+// whose attributes include every pair, e.g. {"size": "XL"}.
+type Sale struct {
+	ID        primitive.ObjectID  `bson:"_id" json:"id"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	ProductID *primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	Variant   map[string]string   `bson:"variant,omitempty" json:"variant,omitempty"`
+	Name      string              `bson:"name" json:"name"`
+	Type      DiscountType        `bson:"type" json:"type"`
+	// Percent is used by percentage sales, Amount by fixed ones
+	Percent   float64      `bson:"percent,omitempty" json:"percent,omitempty"`
+	Amount    *money.Money `bson:"amount,omitempty" json:"amount,omitempty"`
+	StartsAt  time.Time    `bson:"starts_at" json:"starts_at"`
+	EndsAt    time.Time    `bson:"ends_at" json:"ends_at"`
+	CreatedAt time.Time    `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockSubscription asks to be emailed once when an out-of-stock product is restocked
+type StockSubscription struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	Email     string             `bson:"email" json:"-"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ProductSuggestion is the searchable part of a product, kept in step with
+// the catalog by product events. Lowercased copies back the prefix indexes.
+type ProductSuggestion struct {
+	ProductID     string   `bson:"_id" json:"_id"`
+	Title         string   `bson:"title" json:"title"`
+	TitleLower    string   `bson:"title_lower" json:"-"`
+	Terms         []string `bson:"terms" json:"-"`
+	Category      string   `bson:"category,omitempty" json:"category,omitempty"`
+	CategoryLower string   `bson:"category_lower,omitempty" json:"-"`
+	Version       int64    `bson:"version" json:"-"`
+}
+
+// SearchQuery counts how often a normalized query was searched
+type SearchQuery struct {
+	Query          string    `bson:"_id" json:"query"`
+	Count          int64     `bson:"count" json:"count"`
+	LastSearchedAt time.Time `bson:"last_searched_at" json:"-"`
+}
@@ -12,11 +12,50 @@ func ProductRoutes(router *gin.Engine) {
 
 	r.GET("/get",controllers.GetProducts)
 	r.GET("/get/:id",controllers.GetProductByID)
+	r.GET("/:id/reviews", controllers.GetReviews)
+	r.GET("/:id/related", controllers.GetRelatedProducts)
+	r.GET("/:id/bought-together", controllers.GetBoughtTogether)
+	r.GET("/rates", controllers.GetExchangeRates)
+	r.POST("/quote", controllers.QuoteProducts)
+	r.GET("/suggest", controllers.GetSuggestions)
+
+	customerRoute := router.Group("/api/product")
+	customerRoute.Use(middleware.CreateRoleAuthMiddleware("user"))
+	customerRoute.POST("/:id/reviews", controllers.CreateReview)
+	customerRoute.POST("/:id/subscribe", controllers.SubscribeToRestock)
+	customerRoute.DELETE("/:id/subscribe", controllers.UnsubscribeFromRestock)
+
+	adminRoute := router.Group("/api/product")
+	adminRoute.Use(middleware.CreateRoleAuthMiddleware("admin"))
+	adminRoute.PATCH("/reviews/:id/moderate", controllers.ModerateReview)
+	adminRoute.PUT("/rates", controllers.UpdateExchangeRates)
 
 	securedRoute := r.Use(middleware.CreateAuthMiddleware())
 	
 	securedRoute.POST("/create",controllers.CreateProduct)
 	securedRoute.PATCH("/:id" ,controllers.UpdateProduct)
+	securedRoute.DELETE("/:id", controllers.DeleteProduct)
+	securedRoute.PATCH("/:id/stock", controllers.UpdateStock)
+
+	securedRoute.POST("/import", controllers.ImportProducts)
+	securedRoute.GET("/import/:id", controllers.GetImportJob)
+	securedRoute.GET("/export", controllers.ExportProducts)
+	securedRoute.POST("/reviews/:id/reply", controllers.ReplyToReview)
+
+	securedRoute.POST("/sales", controllers.CreateSale)
+	securedRoute.GET("/sales", controllers.GetSales)
+	securedRoute.DELETE("/sales/:id", controllers.DeleteSale)
+
+	inventory := router.Group("/api/product/inventory")
+	inventory.Use(middleware.CreateRoleAuthMiddleware("user", "seller", "admin"))
+
+	// only orderService reserves, forwarding the shopper's token; a shopper
+	// calling directly could hold stock with made-up order IDs
+	inventory.POST("/reserve", middleware.CreateServiceAuthMiddleware(), controllers.ReserveStock)
+	// payment drives commit and release through the InventoryCommit and
+	// InventoryRelease queues; a shopper must not commit an unpaid order
+	inventory.POST("/commit/:orderId", middleware.CreateServiceAuthMiddleware(), controllers.CommitReservation)
+	inventory.POST("/release/:orderId", middleware.CreateServiceAuthMiddleware(), controllers.ReleaseReservation)
 
 	
 
@@ -41,3 +41,17 @@ func UploadImage(file io.Reader) (*uploader.UploadResult, error) {
 
     return res, nil
 }
+
+// UploadImageFromURL lets Cloudinary fetch a remote image by URL
+func UploadImageFromURL(url string) (*uploader.UploadResult, error) {
+    ctx := context.Background()
+
+    res, err := Cloud.Upload.Upload(ctx, url, uploader.UploadParams{
+        Folder: "products",
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return res, nil
+}
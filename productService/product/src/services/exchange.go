@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"supernova/productService/product/src/db"
+	"supernova/productService/product/src/models"
+	"supernova/shared/money"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// exchangeRatesID is the _id of the single rate table document
+const exchangeRatesID = "current"
+
+var (
+	ratesMu sync.RWMutex
+	rates   = models.ExchangeRates{Base: "USD", Rates: map[string]float64{"USD": 1}}
+)
+
+// LoadExchangeRates seeds the rate table from EXCHANGE_RATES_FILE when set,
+// otherwise uses whatever an admin last stored in the database
+func LoadExchangeRates() {
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		table, err := readRatesFile(path)
+		if err != nil {
+			log.Printf("❌ productService failed to load exchange rates from %s: %v", path, err)
+		} else if _, err := SetExchangeRates(table.Base, table.Rates, "file"); err != nil {
+			log.Printf("❌ productService failed to store exchange rates: %v", err)
+		} else {
+			log.Printf("✅ Exchange rates loaded from %s", path)
+			return
+		}
+	}
+
+	if err := refreshExchangeRates(); err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("❌ productService failed to read exchange rates: %v", err)
+	}
+}
+
+// StartExchangeRateRefresher keeps the in-memory table in step with the
+// database so every replica sees manual updates
+func StartExchangeRateRefresher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := refreshExchangeRates(); err != nil && err != mongo.ErrNoDocuments {
+			log.Printf("❌ productService failed to refresh exchange rates: %v", err)
+		}
+	}
+}
+
+// GetExchangeRates returns a copy of the current rate table
+func GetExchangeRates() models.ExchangeRates {
+	ratesMu.RLock()
+	defer ratesMu.RUnlock()
+
+	table := rates
+	table.Rates = make(map[string]float64, len(rates.Rates))
+	for currency, rate := range rates.Rates {
+		table.Rates[currency] = rate
+	}
+	return table
+}
+
+// SetExchangeRates replaces the rate table and persists it
+func SetExchangeRates(base string, table map[string]float64, source string) (models.ExchangeRates, error) {
+	base = strings.ToUpper(base)
+	normalized := make(map[string]float64, len(table)+1)
+	for currency, rate := range table {
+		if rate <= 0 {
+			return models.ExchangeRates{}, fmt.Errorf("rate for %s must be positive", currency)
+		}
+		normalized[strings.ToUpper(currency)] = rate
+	}
+	normalized[base] = 1
+
+	updated := models.ExchangeRates{
+		ID:        exchangeRatesID,
+		Base:      base,
+		Rates:     normalized,
+		Source:    source,
+		UpdatedAt: time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := db.GetExchangeRateCollection().ReplaceOne(ctx, bson.M{"_id": exchangeRatesID}, updated,
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.ExchangeRates{}, err
+	}
+
+	ratesMu.Lock()
+	rates = updated
+	ratesMu.Unlock()
+	return updated, nil
+}
+
+// ConvertPrice converts a price into the target currency
+func ConvertPrice(price money.Money, currency string) (money.Money, error) {
+	to := money.Currency(strings.ToUpper(currency))
+	if price.Currency == to {
+		return price, nil
+	}
+
+	ratesMu.RLock()
+	from, okFrom := rates.Rates[string(price.Currency)]
+	rate, okTo := rates.Rates[string(to)]
+	ratesMu.RUnlock()
+
+	if !okFrom || !okTo {
+		return money.Money{}, fmt.Errorf("no exchange rate from %s to %s", price.Currency, to)
+	}
+	return price.Convert(rate/from, to), nil
+}
+
+func refreshExchangeRates() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var stored models.ExchangeRates
+	if err := db.GetExchangeRateCollection().FindOne(ctx, bson.M{"_id": exchangeRatesID}).Decode(&stored); err != nil {
+		return err
+	}
+
+	ratesMu.Lock()
+	rates = stored
+	ratesMu.Unlock()
+	return nil
+}
+
+func readRatesFile(path string) (models.ExchangeRates, error) {
+	var table models.ExchangeRates
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return table, err
+	}
+	if err := json.Unmarshal(data, &table); err != nil {
+		return table, err
+	}
+	if table.Base == "" || len(table.Rates) == 0 {
+		return table, fmt.Errorf("rate file must define base and rates")
+	}
+	return table, nil
+}
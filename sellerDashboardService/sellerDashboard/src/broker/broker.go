@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"supernova/sellerDashboardService/sellerDashboard/src/controller"
+	"supernova/sellerDashboardService/sellerDashboard/src/dto"
 	"supernova/sellerDashboardService/sellerDashboard/src/models"
 
 	"github.com/streadway/amqp"
@@ -22,7 +23,7 @@ var (
 	retryBackoff = 5 * time.Second
 )
 
-var queues = []string{ "AuthServiceDashboard" , "ProductDashboard" , "OrderDashboard" , "PaymentDashboard"}
+var queues = []string{ "AuthServiceDashboard" , "ProductDashboard" , "ProductEventsDashboard" , "OrderDashboard" , "PaymentDashboard" , "CartAbandonedDashboard" , "OrderStatusDashboard"}
 
 // Connect initializes RabbitMQ connection and channel (idempotent)
 func Connect() {
@@ -191,14 +192,25 @@ func handleMessage(queue string, msg amqp.Delivery) {
 		var product models.Product
 		_ = json.Unmarshal(msg.Body , &product)
 		controller.CreateProduct(product)
+	case "ProductEventsDashboard":
+		var event dto.ProductEvent
+		_ = json.Unmarshal(msg.Body , &event)
+		controller.ApplyProductEvent(event)
 	case "OrderDashboard":
 		var order models.Order
 		_ = json.Unmarshal(msg.Body , &order)
 		controller.CreateOrder(order)
+	case "OrderStatusDashboard":
+		var event dto.OrderStatusEvent
+		_ = json.Unmarshal(msg.Body , &event)
+		controller.ApplyOrderStatus(event)
 	case "PaymentDashboard":
 		var payment models.Payment
 		_ = json.Unmarshal(msg.Body , &payment)
-
+	case "CartAbandonedDashboard":
+		var event dto.CartAbandonedEvent
+		_ = json.Unmarshal(msg.Body , &event)
+		controller.RecordAbandonedCart(event)
 	}
 	msg.Ack(false)
 }
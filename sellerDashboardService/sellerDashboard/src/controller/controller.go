@@ -6,12 +6,15 @@ import (
 	"log"
 	"net/http"
 	"supernova/sellerDashboardService/sellerDashboard/src/db"
+	"supernova/sellerDashboardService/sellerDashboard/src/dto"
 	"supernova/sellerDashboardService/sellerDashboard/src/models"
+	"supernova/shared/money"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -26,12 +29,50 @@ func CreateUser(user models.User) {
 }
 
 func CreateProduct(product models.Product){
-	productCollection := db.GetSellerProductCollection()
+	if product.ID.IsZero() {
+		log.Println("❌ sellerDashboard product event without _id, skipping")
+		return
+	}
+	upsertProduct(product.ID, product.Version, bson.M{"$set": product})
+}
+
+// ApplyProductEvent brings the dashboard's copy of a product up to the event's version
+func ApplyProductEvent(event dto.ProductEvent) {
+	switch event.Type {
+	case "ProductUpdated", "ProductStockChanged":
+		if event.Product != nil {
+			CreateProduct(*event.Product)
+		}
+	case "ProductDeleted":
+		upsertProduct(event.ProductID, event.Version, bson.M{"$set": bson.M{
+			"deleted": true,
+			"version": event.Version,
+		}})
+	}
+}
+
+// upsertProduct applies update only when the stored copy is older than version.
+// A newer copy makes the filter miss and the upsert collide on _id, which is
+// how a stale event is recognised and dropped.
+func upsertProduct(productID primitive.ObjectID, version int64, update bson.M) {
 	ctx , cancle := context.WithTimeout(context.Background() , 10*time.Second)
 	defer cancle()
-	_ , err := productCollection.InsertOne(ctx , product)
+
+	_, err := db.GetSellerProductCollection().UpdateOne(ctx,
+		// copies written before products were versioned have no version yet
+		bson.M{"_id": productID, "$or": bson.A{
+			bson.M{"version": bson.M{"$lt": version}},
+			bson.M{"version": bson.M{"$exists": false}},
+		}},
+		update,
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		log.Printf("ℹ️ sellerDashboard ignored stale event for product %s (version %d)", productID.Hex(), version)
+		return
+	}
 	if err != nil {
-		log.Print("error: %v",err.Error())
+		log.Printf("❌ sellerDashboard failed to apply product %s: %v", productID.Hex(), err)
 	}
 }
 
@@ -56,6 +97,108 @@ func CreatePayment(payment models.Order){
 	}
 }
 
+// ApplyOrderStatus copies an order's status and fulfilment groups from an
+// OrderStatusDashboard event, skipping events older than what is stored
+func ApplyOrderStatus(event dto.OrderStatusEvent) {
+	orderID, err := primitive.ObjectIDFromHex(event.OrderID)
+	if err != nil {
+		log.Println("❌ sellerDashboard invalid OrderStatusDashboard message:", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = db.GetSellerOrderCollection().UpdateOne(ctx,
+		bson.M{"_id": orderID, "updatedAt": bson.M{"$lt": event.UpdatedAt}},
+		bson.M{"$set": bson.M{
+			"status":      event.Status,
+			"fulfilments": event.Fulfilments,
+			"updatedAt":   event.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		log.Printf("❌ sellerDashboard failed to update order %s status: %v", event.OrderID, err)
+	}
+}
+
+// RecordAbandonedCart stores a CartAbandonedDashboard event once per event ID
+func RecordAbandonedCart(event dto.CartAbandonedEvent) {
+	if event.EventID == "" {
+		log.Println("❌ sellerDashboard abandoned cart event without eventId, skipping")
+		return
+	}
+	record := models.AbandonedCart{
+		EventID:     event.EventID,
+		CartID:      event.CartID,
+		UserID:      event.UserID,
+		SellerIDs:   []string{},
+		ProductIDs:  []string{},
+		AbandonedAt: event.AbandonedAt,
+	}
+	sellers := map[string]bool{}
+	for _, item := range event.Items {
+		record.ProductIDs = append(record.ProductIDs, item.ProductID)
+		if item.SellerID != "" && !sellers[item.SellerID] {
+			sellers[item.SellerID] = true
+			record.SellerIDs = append(record.SellerIDs, item.SellerID)
+		}
+	}
+
+	ctx , cancle := context.WithTimeout(context.Background() , 10*time.Second)
+	defer cancle()
+	_, err := db.GetSellerAbandonedCartCollection().InsertOne(ctx, record)
+	if mongo.IsDuplicateKeyError(err) {
+		log.Printf("ℹ️ sellerDashboard ignored redelivered abandoned cart event %s", event.EventID)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ sellerDashboard failed to record abandoned cart %s: %v", event.CartID, err)
+	}
+}
+
+// abandonedCartRate counts the carts holding the seller's products that were
+// abandoned in [from, to) and relates them to the orders for those products
+// placed in the same window: abandoned / (abandoned + ordered)
+func abandonedCartRate(ctx context.Context, sellerID string, from, to time.Time) (int64, float64, error) {
+	window := bson.M{"$gte": from, "$lt": to}
+	abandoned, err := db.GetSellerAbandonedCartCollection().CountDocuments(ctx, bson.M{
+		"sellerIds":   sellerID,
+		"abandonedAt": window,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	productCursor, err := db.GetSellerProductCollection().Find(ctx, bson.M{"seller_id": sellerID})
+	if err != nil {
+		return 0, 0, err
+	}
+	var products []models.Product
+	if err := productCursor.All(ctx, &products); err != nil {
+		return 0, 0, err
+	}
+	productIDs := make([]primitive.ObjectID, 0, len(products))
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+
+	var ordered int64
+	if len(productIDs) > 0 {
+		ordered, err = db.GetSellerOrderCollection().CountDocuments(ctx, bson.M{
+			"items.productId": bson.M{"$in": productIDs},
+			"createdAt":       window,
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if abandoned+ordered == 0 {
+		return 0, 0, nil
+	}
+	return abandoned, float64(abandoned) / float64(abandoned+ordered), nil
+}
+
 
 // func GetMetrics(c *gin.Context) {
 // 	// sellerID, exists := c.Get("UserID")
@@ -118,10 +261,22 @@ func CreatePayment(payment models.Order){
 
 
 
+// revenue keeps a separate exact total per currency; amounts in
+// different currencies are never added together
+type revenue map[money.Currency]money.Money
+
+func (r revenue) add(amount money.Money) {
+	total, ok := r[amount.Currency]
+	if !ok {
+		total = money.Zero(amount.Currency)
+	}
+	r[amount.Currency], _ = total.Add(amount)
+}
+
 type TopProduct struct {
 	ProductID primitive.ObjectID `json:"productId"`
 	SoldUnits int                `json:"soldUnits"`
-	Revenue   float64            `json:"revenue"`
+	Revenue   revenue            `json:"revenue"`
 }
 
 func GetMetrics(c *gin.Context) {
@@ -157,7 +312,7 @@ func GetMetrics(c *gin.Context) {
 	}
 
 	// --- Calculate totals and top product ---
-	totalRevenue := 0.0
+	totalRevenue := revenue{}
 	totalSalesCount := 0
 
 	// Map[productID] -> TopProduct
@@ -166,17 +321,18 @@ func GetMetrics(c *gin.Context) {
 	for _, order := range orders {
 		for _, item := range order.Items {
 			// Only consider items belonging to this seller (optional: if Product info available)
-			totalRevenue += item.Price.Amount * float64(item.Quantity)
+			lineTotal := item.SettlementPrice.Mul(int64(item.Quantity))
+			totalRevenue.add(lineTotal)
 			totalSalesCount += item.Quantity
 
 			if tp, ok := productStats[item.ProductID]; ok {
 				tp.SoldUnits += item.Quantity
-				tp.Revenue += item.Price.Amount * float64(item.Quantity)
+				tp.Revenue.add(lineTotal)
 			} else {
 				productStats[item.ProductID] = &TopProduct{
 					ProductID: item.ProductID,
 					SoldUnits: item.Quantity,
-					Revenue:   item.Price.Amount * float64(item.Quantity),
+					Revenue:   revenue{lineTotal.Currency: lineTotal},
 				}
 			}
 		}
@@ -190,11 +346,21 @@ func GetMetrics(c *gin.Context) {
 		}
 	}
 
+	sellerID, _ := c.Get("UserID")
+	abandonedCarts, abandonedRate, err := abandonedCartRate(ctx, fmt.Sprintf("%v", sellerID), firstOfMonth, firstOfNextMonth)
+	if err != nil {
+		log.Println("❌ sellerDashboard failed to compute abandoned cart rate:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get abandoned carts"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"totalRevenue":    totalRevenue,
-		"totalSalesCount": totalSalesCount,
-		"ordersCount":     len(orders),
-		"topProduct":      topProduct,
+		"totalRevenue":      totalRevenue,
+		"totalSalesCount":   totalSalesCount,
+		"ordersCount":       len(orders),
+		"topProduct":        topProduct,
+		"abandonedCarts":    abandonedCarts,
+		"abandonedCartRate": abandonedRate,
 	})
 }
 
@@ -226,22 +392,18 @@ func GetOrders(c *gin.Context) {
 		return
 	}
 
-	if len(products) == 0 {
-		c.JSON(http.StatusOK, []models.Order{}) // no products, return empty list
-		return
-	}
-
 	productIDs := make([]primitive.ObjectID, 0, len(products))
 	for _, p := range products {
-		id, err := primitive.ObjectIDFromHex(p.SellerID) // adjust if your Product._id is ObjectID
-		if err == nil {
-			productIDs = append(productIDs, id)
-		}
+		productIDs = append(productIDs, p.ID)
 	}
 
-	// --- 2️⃣ Get all orders containing seller's products ---
+	// --- 2️⃣ Get orders with a fulfilment group for this seller; orders from
+	// before groups existed are found by the seller's products ---
 	orderFilter := bson.M{
-		"items.productId": bson.M{"$in": productIDs},
+		"$or": []bson.M{
+			{"fulfilments.sellerId": sellerIDStr},
+			{"fulfilments": bson.M{"$exists": false}, "items.productId": bson.M{"$in": productIDs}},
+		},
 	}
 
 	orderCursor, err := db.GetSellerOrderCollection().Find(ctx, orderFilter)
@@ -259,20 +421,29 @@ func GetOrders(c *gin.Context) {
 		return
 	}
 
-	// --- 3️⃣ Filter order items to only include those from this seller ---
+	ownProducts := make(map[primitive.ObjectID]bool, len(productIDs))
+	for _, id := range productIDs {
+		ownProducts[id] = true
+	}
+
+	// --- 3️⃣ Keep only this seller's items and group ---
 	filteredOrders := make([]models.Order, 0)
 	for _, order := range orders {
 		filteredItems := make([]models.Item, 0)
 		for _, item := range order.Items {
-			for _, pid := range productIDs {
-				if item.ProductID == pid {
-					filteredItems = append(filteredItems, item)
-					break
-				}
+			if item.SellerID == sellerIDStr || (item.SellerID == "" && ownProducts[item.ProductID]) {
+				filteredItems = append(filteredItems, item)
+			}
+		}
+		var ownGroups []models.FulfilmentGroup
+		for _, group := range order.Fulfilments {
+			if group.SellerID == sellerIDStr {
+				ownGroups = append(ownGroups, group)
 			}
 		}
 		if len(filteredItems) > 0 {
 			order.Items = filteredItems
+			order.Fulfilments = ownGroups
 			filteredOrders = append(filteredOrders, order)
 		}
 	}
@@ -280,7 +451,6 @@ func GetOrders(c *gin.Context) {
 	c.JSON(http.StatusOK, filteredOrders)
 }
 
-
 func GetProducts(c *gin.Context) {
 	sellerID, exists := c.Get("UserID")
 	if !exists {
@@ -296,7 +466,7 @@ func GetProducts(c *gin.Context) {
 	findOptions := options.Find()
 	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
 
-	cursor, err := db.GetSellerProductCollection().Find(ctx, bson.M{"seller_id": sellerIDStr}, findOptions)
+	cursor, err := db.GetSellerProductCollection().Find(ctx, bson.M{"seller_id": sellerIDStr, "deleted": bson.M{"$ne": true}}, findOptions)
 	if err != nil {
 		log.Println("❌ Error fetching products:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch products"})
@@ -6,6 +6,7 @@ var sellerUserCollection *mongo.Collection
 var sellerOrderCollection *mongo.Collection
 var sellerPaymentCollection *mongo.Collection
 var sellerProductCollection *mongo.Collection
+var sellerAbandonedCartCollection *mongo.Collection
 
 func GetSellerUserCollection() *mongo.Collection {
 	return sellerUserCollection
@@ -21,4 +22,8 @@ func GetSellerPaymentCollection()*mongo.Collection{
 
 func GetSellerProductCollection() *mongo.Collection{
 	return sellerProductCollection 
-}
\ No newline at end of file
+}
+
+func GetSellerAbandonedCartCollection() *mongo.Collection {
+	return sellerAbandonedCartCollection
+}
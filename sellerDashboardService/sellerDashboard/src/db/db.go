@@ -30,4 +30,5 @@ func InitDB() {
 	sellerOrderCollection = client.Database("SupernovaSellerDashboardDB").Collection("order")
 	sellerPaymentCollection = client.Database("SupernovaSellerDashboardDB").Collection("payment")
 	sellerProductCollection = client.Database("SupernovaSellerDashboardDB").Collection("product")
+	sellerAbandonedCartCollection = client.Database("SupernovaSellerDashboardDB").Collection("abandonedCart")
 }
\ No newline at end of file
@@ -1,6 +1,10 @@
 package dto
 
 import(
+	"supernova/sellerDashboardService/sellerDashboard/src/models"
+	"supernova/shared/money"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
  	"github.com/golang-jwt/jwt/v5"
 
@@ -18,12 +22,41 @@ type JsonUser struct {
 	Email string `json:"email"`
 }
 
+// ProductEvent is the payload of the ProductEventsDashboard queue
+type ProductEvent struct {
+	Type      string             `json:"type"`
+	ProductID primitive.ObjectID `json:"_id"`
+	Version   int64              `json:"version"`
+	Product   *models.Product    `json:"product,omitempty"`
+}
+
 type PaymentData struct {
 	ReceiverMail string 			`json:"receiverMail"`
 	PaymentID  	primitive.ObjectID `json:"paymentID"`
 	OrderID		primitive.ObjectID `json:"orderID"`
-	Amount 		float64				`json:"amount"`
-	Currency    string				`json:"currency"`
+	Amount 		money.Money			`json:"amount"`
 }
 
+// AbandonedItem is one line of an abandoned cart
+type AbandonedItem struct {
+	ProductID string `json:"productId"`
+	SellerID  string `json:"sellerId"`
+	Quantity  int    `json:"quantity"`
+}
 
+// CartAbandonedEvent is the payload of the CartAbandonedDashboard queue
+type CartAbandonedEvent struct {
+	EventID     string          `json:"eventId"`
+	CartID      string          `json:"cartId"`
+	UserID      string          `json:"userId"`
+	Items       []AbandonedItem `json:"items"`
+	AbandonedAt time.Time       `json:"abandonedAt"`
+}
+
+// OrderStatusEvent is the payload of the OrderStatusDashboard queue
+type OrderStatusEvent struct {
+	OrderID     string                   `json:"orderId"`
+	Status      models.OrderStatus       `json:"status"`
+	Fulfilments []models.FulfilmentGroup `json:"fulfilments"`
+	UpdatedAt   time.Time                `json:"updatedAt"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AbandonedCart records one reminder cartService sent for an idle cart. The
+// _id is the event ID, so a redelivered event is stored only once.
+type AbandonedCart struct {
+	EventID     string    `bson:"_id" json:"eventId"`
+	CartID      string    `bson:"cartId" json:"cartId"`
+	UserID      string    `bson:"userId" json:"userId"`
+	SellerIDs   []string  `bson:"sellerIds" json:"sellerIds"`
+	ProductIDs  []string  `bson:"productIds" json:"productIds"`
+	AbandonedAt time.Time `bson:"abandonedAt" json:"abandonedAt"`
+}
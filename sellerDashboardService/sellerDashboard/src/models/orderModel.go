@@ -1,6 +1,7 @@
 package models
 
 import (
+	"supernova/shared/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,8 +13,9 @@ type Order struct {
 	OrderID         primitive.ObjectID `json:"orderId" bson:"_id" binding:"required"`
 	UserID          primitive.ObjectID `json:"userId" bson:"userId" binding:"required"`
 	Items           []Item    	    	`json:"items" bson:"items" binding:"required"`
-	TotalPrice     	Price            	`json:"totalPrice" bson:"totalPrice" binding:"required"`
+	TotalPrice     	money.Money        	`json:"totalPrice" bson:"totalPrice" binding:"required"`
 	Status          OrderStatus        `json:"status" bson:"status"`
+	Fulfilments     []FulfilmentGroup  `json:"fulfilments" bson:"fulfilments,omitempty"`
 	Address			Address    		   `json:"address" bson:"address" binding:"required"`
 	CreatedAt       time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
 	UpdatedAt       time.Time          `json:"updatedAt" bson:"updatedAt" binding:"required"`
@@ -22,20 +24,31 @@ type Order struct {
 // OrderItem represents a single product within an order.
 type Item struct {
 	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
-	Price     Price            `bson:"price" json:"price"`
+	SellerID  string             `bson:"sellerId,omitempty" json:"sellerId,omitempty"`
+	Price     money.Money        `bson:"price" json:"price"`
+	SettlementPrice money.Money `bson:"settlementPrice" json:"settlementPrice"`
 	Quantity  int                `bson:"quantity" json:"quantity" binding:"required,min=1"`
 }
 
-type Price struct {
-	Amount   float64 `json:"amount" bson:"amount" binding:"required"`
-	Currency Currency  `json:"currency" bson:"currency" binding:"required"`
+
+// FulfilmentGroup is the part of an order one seller ships
+type FulfilmentGroup struct {
+	SellerID    string      `json:"sellerId" bson:"sellerId"`
+	Status      OrderStatus `json:"status" bson:"status"`
+	Tracking    *Tracking   `json:"tracking,omitempty" bson:"tracking,omitempty"`
+	ConfirmedAt *time.Time  `json:"confirmedAt,omitempty" bson:"confirmedAt,omitempty"`
+	ShippedAt   *time.Time  `json:"shippedAt,omitempty" bson:"shippedAt,omitempty"`
+	DeliveredAt *time.Time  `json:"deliveredAt,omitempty" bson:"deliveredAt,omitempty"`
+	CancelledAt *time.Time  `json:"cancelledAt,omitempty" bson:"cancelledAt,omitempty"`
+	UpdatedAt   time.Time   `json:"updatedAt" bson:"updatedAt"`
 }
 
-type Currency string
-const (
-	USD Currency = "USD"
-	INR Currency = "INR"
-)
+// Tracking is how a shopper follows a shipped group
+type Tracking struct {
+	Carrier string `json:"carrier" bson:"carrier"`
+	Number  string `json:"number" bson:"number"`
+	URL     string `json:"url,omitempty" bson:"url,omitempty"`
+}
 
 // ShippingAddress represents the delivery location for an order.
 
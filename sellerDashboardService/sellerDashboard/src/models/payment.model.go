@@ -1,6 +1,7 @@
 package models
 
 import (
+	"supernova/shared/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,7 +16,7 @@ type Payment struct {
 	// Signature 		string             	`bson:"signature" json:"signature"`
 	Status    		Status             	`bson:"status" json:"status"`
 	UserID   		primitive.ObjectID 	`bson:"userID" json:"userID"`
-	Price           Price 				`bson:"price" json:"price"`
+	Price           money.Money			`bson:"price" json:"price"`
 	CreatedAt 		time.Time			`bson:"createdAt" json:"createdAt"`
 	UpdatedAt 		time.Time	 		`bson:"updatedAt" json:"updatedAt"`
 }
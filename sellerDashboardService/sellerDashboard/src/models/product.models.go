@@ -1,7 +1,11 @@
 package models
 
+import (
+    "supernova/shared/money"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
 
-// import "go.mongodb.org/mongo-driver/bson/primitive"
 
 // Price sub-struct
 // type Price struct {
@@ -18,11 +22,15 @@ type Image struct {
 
 // Product model
 type Product struct {
+    ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
     Title       string             `bson:"title" json:"title" binding:"required"`
     Description string             `bson:"description" json:"description"`
-    Price       Price              `bson:"price" json:"price"  binding:"required"`
+    Price       money.Money        `bson:"price" json:"price"  binding:"required"`
     Images      []Image            `bson:"images" json:"images" binding:"required"`
     Stock       int                `bson:"stock" json:"stock" binding:"required,gte=0"`
     SellerID    string             `bson:"seller_id" json:"seller_id" binding:"required"`
+    Version     int64              `bson:"version" json:"version"`
+    // Deleted keeps a tombstone so a late, older update can't bring the product back
+    Deleted     bool               `bson:"deleted,omitempty" json:"-"`
 }
 
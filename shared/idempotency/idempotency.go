@@ -0,0 +1,171 @@
+// Package idempotency makes retried POSTs safe. A client sends an
+// Idempotency-Key header; the first request with that key runs and its
+// response is stored, and every later request with the same key gets that
+// response replayed instead of running again. Reusing a key for a different
+// request is rejected.
+//
+// Keys are scoped to the signed-in user, so Middleware must run after the
+// service's auth middleware.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength       = 255
+	defaultTTL         = 24 * time.Hour
+	defaultLockTimeout = time.Minute
+)
+
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+// Record is what is kept under a key: the fingerprint of the request that
+// claimed it and, once it finished, the response to replay
+type Record struct {
+	Key         string `bson:"_id"`
+	Fingerprint string `bson:"fingerprint"`
+	Status      string `bson:"status"`
+	StatusCode  int    `bson:"statusCode,omitempty"`
+	ContentType string `bson:"contentType,omitempty"`
+	Body        []byte `bson:"body,omitempty"`
+	// LockedUntil is when a processing record whose request died may be taken over
+	LockedUntil time.Time `bson:"lockedUntil"`
+	CreatedAt   time.Time `bson:"createdAt"`
+	ExpiresAt   time.Time `bson:"expiresAt"`
+}
+
+// Store keeps records. Claim must be atomic: of two requests claiming the
+// same key at once exactly one gets claimed=true.
+type Store interface {
+	// Claim stores a processing record for key unless one exists. When it
+	// does, claimed is false and the stored record is returned, except that
+	// a processing record with the same fingerprint whose lock ran out is
+	// taken over.
+	Claim(ctx context.Context, key, fingerprint string, lock, ttl time.Duration) (record Record, claimed bool, err error)
+	// Complete stores the response of the request holding key
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	// Release drops key so the request can be tried again
+	Release(ctx context.Context, key string) error
+}
+
+// Middleware replays stored responses for repeated Idempotency-Key headers.
+// Requests without the header run as usual. A response with a 5xx status is
+// not stored, so a request that failed on the server can be retried with
+// the same key.
+func Middleware(store Store) gin.HandlerFunc {
+	ttl := durationFromEnv("IDEMPOTENCY_TTL", defaultTTL)
+	lock := durationFromEnv("IDEMPOTENCY_LOCK_TIMEOUT", defaultLockTimeout)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scopedKey := c.GetString("UserID") + ":" + key
+		fingerprint := Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		record, claimed, err := store.Claim(ctx, scopedKey, fingerprint, lock, ttl)
+		cancel()
+		if err != nil {
+			log.Printf("❌ idempotency claim failed: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Could not check Idempotency-Key, please retry"})
+			return
+		}
+
+		if !claimed {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request"})
+			case record.Status == StatusProcessing:
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+			default:
+				c.Header(HeaderReplayed, "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// the request's own context may be done by now
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if recorder.Status() >= http.StatusInternalServerError {
+			err = store.Release(ctx, scopedKey)
+		} else {
+			err = store.Complete(ctx, scopedKey, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("❌ idempotency failed to store response for %s: %v", c.Request.URL.Path, err)
+		}
+	}
+}
+
+// Fingerprint identifies a request by method, path and body
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response body while writing it out
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
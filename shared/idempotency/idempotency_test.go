@@ -0,0 +1,202 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryStore is a Store held in a map, following MongoStore's rules
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]Record)}
+}
+
+func (s *memoryStore) Claim(ctx context.Context, key, fingerprint string, lock, ttl time.Duration) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	existing, ok := s.records[key]
+	if !ok {
+		record := Record{Key: key, Fingerprint: fingerprint, Status: StatusProcessing, LockedUntil: now.Add(lock), CreatedAt: now, ExpiresAt: now.Add(ttl)}
+		s.records[key] = record
+		return record, true, nil
+	}
+	if existing.Fingerprint == fingerprint && existing.Status == StatusProcessing && existing.LockedUntil.Before(now) {
+		existing.LockedUntil = now.Add(lock)
+		s.records[key] = existing
+		return existing, true, nil
+	}
+	return existing, false, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	record.Status = StatusCompleted
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Body = append([]byte(nil), body...)
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.records[key].Status == StatusProcessing {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// newRouter serves POST /orders behind Middleware as the user in the
+// X-User header, answering with whatever handler writes
+func newRouter(store Store, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/orders", func(c *gin.Context) {
+		c.Set("UserID", c.GetHeader("X-User"))
+	}, Middleware(store), handler)
+	return router
+}
+
+func post(router http.Handler, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddlewareReplaysStoredResponse(t *testing.T) {
+	calls := 0
+	router := newRouter(newMemoryStore(), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"order": calls})
+	})
+
+	first := post(router, "u1", "k1", `{"item":1}`)
+	if first.Code != http.StatusCreated || first.Header().Get(HeaderReplayed) != "" {
+		t.Fatalf("first request = %d %q, want 201 and not replayed", first.Code, first.Header().Get(HeaderReplayed))
+	}
+
+	replay := post(router, "u1", "k1", `{"item":1}`)
+	if replay.Code != http.StatusCreated || replay.Header().Get(HeaderReplayed) != "true" {
+		t.Errorf("replay = %d %q, want 201 and replayed", replay.Code, replay.Header().Get(HeaderReplayed))
+	}
+	if replay.Body.String() != first.Body.String() || replay.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replayed %q (%s), want %q (%s)", replay.Body, replay.Header().Get("Content-Type"), first.Body, first.Header().Get("Content-Type"))
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+
+	// keys are per user, and requests without a key always run
+	if w := post(router, "u2", "k1", `{"item":1}`); w.Code != http.StatusCreated || w.Header().Get(HeaderReplayed) != "" {
+		t.Errorf("same key for another user = %d, replayed %q; want a fresh 201", w.Code, w.Header().Get(HeaderReplayed))
+	}
+	post(router, "u1", "", `{"item":1}`)
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want 3", calls)
+	}
+}
+
+func TestMiddlewareRejectsKeyReusedForDifferentBody(t *testing.T) {
+	calls := 0
+	router := newRouter(newMemoryStore(), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	post(router, "u1", "k1", `{"item":1}`)
+	if w := post(router, "u1", "k1", `{"item":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key = %d, want 422", w.Code)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestMiddlewareRejectsKeyInFlight(t *testing.T) {
+	entered := make(chan struct{})
+	finish := make(chan struct{})
+	router := newRouter(newMemoryStore(), func(c *gin.Context) {
+		close(entered)
+		<-finish
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(router, "u1", "k1", `{"item":1}`) }()
+	<-entered
+
+	w := post(router, "u1", "k1", `{"item":1}`)
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("request while in flight = %d, Retry-After %q; want 409 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(finish)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", first.Code)
+	}
+	if w := post(router, "u1", "k1", `{"item":1}`); w.Header().Get(HeaderReplayed) != "true" {
+		t.Errorf("request after completion was not replayed (%d)", w.Code)
+	}
+}
+
+func TestMiddlewareReleasesKeyAfterServerError(t *testing.T) {
+	calls := 0
+	router := newRouter(newMemoryStore(), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "down"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	if w := post(router, "u1", "k1", `{"item":1}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first request = %d, want 500", w.Code)
+	}
+	if w := post(router, "u1", "k1", `{"item":1}`); w.Code != http.StatusCreated || w.Header().Get(HeaderReplayed) != "" {
+		t.Errorf("retry after 500 = %d, replayed %q; want a fresh 201", w.Code, w.Header().Get(HeaderReplayed))
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+
+	// a 4xx is the request's own fault and is stored like a success
+	router = newRouter(newMemoryStore(), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad"})
+	})
+	post(router, "u1", "k1", `{}`)
+	if w := post(router, "u1", "k1", `{}`); w.Code != http.StatusBadRequest || w.Header().Get(HeaderReplayed) != "true" {
+		t.Errorf("retry after 400 = %d, replayed %q; want the stored 400", w.Code, w.Header().Get(HeaderReplayed))
+	}
+}
+
+func TestMiddlewareRejectsLongKey(t *testing.T) {
+	router := newRouter(newMemoryStore(), func(c *gin.Context) { c.Status(http.StatusCreated) })
+	if w := post(router, "u1", strings.Repeat("k", maxKeyLength+1), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("long key = %d, want 400", w.Code)
+	}
+}
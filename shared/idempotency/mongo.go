@@ -0,0 +1,92 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore keeps records in a service's own Mongo collection
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoStore keeps records in collection
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+// EnsureIndexes makes Mongo drop records once they expire
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (s *MongoStore) Claim(ctx context.Context, key, fingerprint string, lock, ttl time.Duration) (Record, bool, error) {
+	now := time.Now()
+	record := Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      StatusProcessing,
+		LockedUntil: now.Add(lock),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	_, err := s.collection.InsertOne(ctx, record)
+	if err == nil {
+		return record, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return Record{}, false, err
+	}
+
+	// the same request whose holder died may take the key over
+	var existing Record
+	err = s.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":         key,
+			"fingerprint": fingerprint,
+			"status":      StatusProcessing,
+			"lockedUntil": bson.M{"$lt": now},
+		},
+		bson.M{"$set": bson.M{"lockedUntil": now.Add(lock)}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&existing)
+	if err == nil {
+		return existing, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return Record{}, false, err
+	}
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		// released or expired in between; the client can simply retry
+		return Record{Key: key, Fingerprint: fingerprint, Status: StatusProcessing}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+	return existing, false, nil
+}
+
+func (s *MongoStore) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{
+		"status":      StatusCompleted,
+		"statusCode":  statusCode,
+		"contentType": contentType,
+		"body":        body,
+	}})
+	return err
+}
+
+func (s *MongoStore) Release(ctx context.Context, key string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": key, "status": StatusProcessing})
+	return err
+}
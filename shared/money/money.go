@@ -0,0 +1,194 @@
+// Package money is the shared representation of prices and totals.
+//
+// Amounts are stored as an integer count of the currency's minor unit
+// (cents, paise) so sums and comparisons are exact. Anything that produces
+// a fractional minor unit (parsing a decimal, applying an exchange rate or
+// a percentage) rounds half to even, the same rule MongoDB's $round uses.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	USD Currency = "USD"
+	INR Currency = "INR"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	JPY Currency = "JPY"
+)
+
+// exponents is the number of minor-unit digits of each supported currency
+var exponents = map[Currency]int{
+	USD: 2,
+	INR: 2,
+	EUR: 2,
+	GBP: 2,
+	JPY: 0,
+}
+
+// ErrCurrencyMismatch is returned when combining amounts in different currencies
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// Money is an amount in minor units of Currency
+type Money struct {
+	Amount   int64    `bson:"amount" json:"amount"`
+	Currency Currency `bson:"currency" json:"currency"`
+}
+
+// New returns an amount already expressed in minor units
+func New(minor int64, currency Currency) Money {
+	return Money{Amount: minor, Currency: currency}
+}
+
+// Zero returns a zero amount in the currency
+func Zero(currency Currency) Money {
+	return Money{Currency: currency}
+}
+
+// FromMajor converts a decimal major-unit amount such as 19.99 into minor units
+func FromMajor(amount float64, currency Currency) Money {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	return Money{Amount: round(r.Mul(r, scale(currency.Exponent()))), Currency: currency}
+}
+
+// Parse reads a decimal major-unit string such as "19.99" without going through float64
+func Parse(amount string, currency Currency) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return Money{}, fmt.Errorf("money: invalid amount %q", amount)
+	}
+	return Money{Amount: round(r.Mul(r, scale(currency.Exponent()))), Currency: currency}, nil
+}
+
+// Exponent returns the currency's minor-unit digits, defaulting to two
+func (c Currency) Exponent() int {
+	if exp, ok := exponents[c]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Valid reports whether the currency is one this package knows about
+func (c Currency) Valid() bool {
+	_, ok := exponents[c]
+	return ok
+}
+
+// Major returns the amount in major units, for display and third-party APIs only
+func (m Money) Major() float64 {
+	f, _ := new(big.Rat).SetFrac(big.NewInt(m.Amount), scale(m.Currency.Exponent()).Num()).Float64()
+	return f
+}
+
+// String formats the amount as "19.99 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+// Decimal formats the amount in major units without the currency, e.g. "19.99"
+func (m Money) Decimal() string {
+	exp := m.Currency.Exponent()
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns m + o; both must share a currency
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o; both must share a currency
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Mul multiplies by an integer quantity
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// Percent returns percent% of the amount, rounded half to even
+func (m Money) Percent(percent float64) Money {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(percent, 'f', -1, 64))
+	r.Mul(r, new(big.Rat).SetInt64(m.Amount))
+	r.Quo(r, big.NewRat(100, 1))
+	return Money{Amount: round(r), Currency: m.Currency}
+}
+
+// Convert applies an exchange rate (units of `to` per unit of m.Currency)
+func (m Money) Convert(rate float64, to Currency) Money {
+	if m.Currency == to {
+		return m
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	r.Mul(r, new(big.Rat).SetInt64(m.Amount))
+	r.Mul(r, scale(to.Exponent()))
+	r.Quo(r, scale(m.Currency.Exponent()))
+	return Money{Amount: round(r), Currency: to}
+}
+
+// Sum adds amounts that all share the given currency
+func Sum(currency Currency, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func scale(exp int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+}
+
+// round rounds a rational to the nearest integer, ties to even
+func round(r *big.Rat) int64 {
+	num, den := r.Num(), r.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// compare 2|rem| with the denominator to find which side of .5 we're on
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	switch twice.Cmp(den) {
+	case 1:
+		quo.Add(quo, big.NewInt(int64(num.Sign())))
+	case 0:
+		if quo.Bit(0) == 1 {
+			quo.Add(quo, big.NewInt(int64(num.Sign())))
+		}
+	}
+	return quo.Int64()
+}
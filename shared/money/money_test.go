@@ -0,0 +1,101 @@
+package money
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFromMajor(t *testing.T) {
+	tests := []struct {
+		major    float64
+		currency Currency
+		want     int64
+	}{
+		{19.99, USD, 1999},
+		{0.1, USD, 10},
+		{1.005, USD, 100}, // tie, rounds to even
+		{1.015, USD, 102},
+		{1.025, USD, 102},
+		{2.675, USD, 268}, // not 267, which float math gives
+		{-1.005, USD, -100},
+		{-1.015, USD, -102},
+		{-0.005, USD, 0},
+		{12345678901.23, USD, 1234567890123},
+		{99999999999.99, INR, 9999999999999},
+		{1234.5, JPY, 1234},
+		{1235.5, JPY, 1236},
+		{19.99, Currency("XYZ"), 1999}, // unknown currencies default to two digits
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v %s", tt.major, tt.currency), func(t *testing.T) {
+			got := FromMajor(tt.major, tt.currency)
+			if got.Amount != tt.want || got.Currency != tt.currency {
+				t.Errorf("FromMajor(%v, %s) = %v, want %d %s", tt.major, tt.currency, got, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount  string
+		want    int64
+		wantErr bool
+	}{
+		{"19.99", 1999, false},
+		{" 19.99 ", 1999, false},
+		{"1.005", 100, false},
+		{"1.0051", 101, false},
+		{"-2.675", -268, false},
+		{"92233720368547758.07", 9223372036854775807, false},
+		{"", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			got, err := Parse(tt.amount, USD)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.amount, err, tt.wantErr)
+			}
+			if err == nil && got.Amount != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.amount, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(1999, USD), "19.99"},
+		{New(5, USD), "0.05"},
+		{New(-5, USD), "-0.05"},
+		{New(-100, USD), "-1.00"},
+		{New(1234, JPY), "1234"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestPercentAndConvert(t *testing.T) {
+	if got := New(1999, USD).Percent(12.5); got.Amount != 250 { // 249.875
+		t.Errorf("Percent(12.5) of 19.99 = %d, want 250", got.Amount)
+	}
+	if got := New(250, USD).Percent(5); got.Amount != 12 { // 12.5, ties to even
+		t.Errorf("Percent(5) of 2.50 = %d, want 12", got.Amount)
+	}
+	if got := New(1999, USD).Convert(83.12, INR); got.Amount != 166157 || got.Currency != INR {
+		t.Errorf("Convert(83.12) of 19.99 USD = %v, want 1661.57 INR", got)
+	}
+	if got := New(1000, USD).Convert(151.235, JPY); got.Amount != 1512 {
+		t.Errorf("Convert(151.235) of 10.00 USD = %v, want 1512 JPY", got)
+	}
+	if _, err := New(1, USD).Add(New(1, INR)); err != ErrCurrencyMismatch {
+		t.Errorf("Add across currencies err = %v, want ErrCurrencyMismatch", err)
+	}
+}
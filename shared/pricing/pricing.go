@@ -0,0 +1,238 @@
+// Package pricing turns priced cart lines into the totals a shopper pays.
+//
+// The cart service shows the result and the order service charges it, so
+// both must call Calculate with the same inputs rather than summing on their
+// own. Every amount in a Summary is in the settlement currency: each line is
+// converted first and then multiplied, discounted and taxed, so rounding
+// happens the same way wherever the total is computed.
+package pricing
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"supernova/shared/money"
+)
+
+// Line is one product line as priced now, in the product's own currency.
+// Discount is the line's share of a coupon in that same currency.
+type Line struct {
+	ProductID string
+	UnitPrice money.Money
+	Quantity  int
+	Discount  money.Money
+}
+
+// Destination is where the order ships; tax and shipping depend on it
+type Destination struct {
+	Country string `json:"country"`
+	State   string `json:"state,omitempty"`
+}
+
+// LineSummary is one line's share of the totals
+type LineSummary struct {
+	ProductID string      `json:"productId"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice money.Money `json:"unitPrice"`
+	Subtotal  money.Money `json:"subtotal"`
+	Discount  money.Money `json:"discount"`
+	Tax       money.Money `json:"tax"`
+	Total     money.Money `json:"total"`
+}
+
+// Summary is the full price breakdown. Destination is nil when no address
+// was known, in which case tax and shipping assume the home country.
+type Summary struct {
+	Currency      money.Currency             `json:"currency"`
+	Lines         []LineSummary              `json:"lines"`
+	Subtotal      money.Money                `json:"subtotal"`
+	Discount      money.Money                `json:"discount"`
+	TaxRate       float64                    `json:"taxRate"`
+	Tax           money.Money                `json:"tax"`
+	Shipping      money.Money                `json:"shipping"`
+	GrandTotal    money.Money                `json:"grandTotal"`
+	Destination   *Destination               `json:"destination"`
+	ExchangeRates map[money.Currency]float64 `json:"exchangeRates,omitempty"`
+}
+
+// RateFunc returns how many units of the settlement currency one unit of from buys
+type RateFunc func(from money.Currency) (float64, error)
+
+// defaultTaxRates are percentages by country, or by COUNTRY-STATE where a
+// state sets its own; TAX_RATES overrides them, e.g. "IN=18,US-CA=7.25"
+var defaultTaxRates = map[string]float64{
+	"IN": 18,
+}
+
+// countryAliases maps the country names shoppers type to ISO codes
+var countryAliases = map[string]string{
+	"INDIA":                    "IN",
+	"USA":                      "US",
+	"UNITED STATES":            "US",
+	"UNITED STATES OF AMERICA": "US",
+}
+
+// SettlementCurrency is the single currency every order is charged in
+func SettlementCurrency() money.Currency {
+	if currency := os.Getenv("SETTLEMENT_CURRENCY"); currency != "" {
+		return money.Currency(strings.ToUpper(currency))
+	}
+	return money.INR
+}
+
+// ExchangeRate reads a rate table (units of each currency per one unit of a
+// common base) and returns how many units of `to` one unit of `from` buys
+func ExchangeRate(rates map[string]float64, from, to money.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	fromRate, okFrom := rates[string(from)]
+	toRate, okTo := rates[string(to)]
+	if !okFrom || !okTo {
+		return 0, fmt.Errorf("no exchange rate from %s to %s", from, to)
+	}
+	return toRate / fromRate, nil
+}
+
+// Calculate prices lines in currency for shipping to dest
+func Calculate(lines []Line, currency money.Currency, rate RateFunc, dest *Destination) (Summary, error) {
+	summary := Summary{
+		Currency:      currency,
+		Lines:         make([]LineSummary, 0, len(lines)),
+		Subtotal:      money.Zero(currency),
+		Discount:      money.Zero(currency),
+		Tax:           money.Zero(currency),
+		Shipping:      money.Zero(currency),
+		GrandTotal:    money.Zero(currency),
+		Destination:   dest,
+		ExchangeRates: map[money.Currency]float64{},
+	}
+	summary.TaxRate = taxRate(dest)
+
+	for _, line := range lines {
+		r, err := rate(line.UnitPrice.Currency)
+		if err != nil {
+			return Summary{}, err
+		}
+		summary.ExchangeRates[line.UnitPrice.Currency] = r
+
+		unitPrice := line.UnitPrice.Convert(r, currency)
+		subtotal := unitPrice.Mul(int64(line.Quantity))
+		discount := money.Zero(currency)
+		if line.Discount.Amount > 0 {
+			discount = line.Discount.Convert(r, currency)
+			if discount.Amount > subtotal.Amount {
+				discount = subtotal
+			}
+		}
+		taxable, _ := subtotal.Sub(discount)
+		tax := taxable.Percent(summary.TaxRate)
+		total, _ := taxable.Add(tax)
+
+		summary.Lines = append(summary.Lines, LineSummary{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			UnitPrice: unitPrice,
+			Subtotal:  subtotal,
+			Discount:  discount,
+			Tax:       tax,
+			Total:     total,
+		})
+		summary.Subtotal, _ = summary.Subtotal.Add(subtotal)
+		summary.Discount, _ = summary.Discount.Add(discount)
+		summary.Tax, _ = summary.Tax.Add(tax)
+	}
+
+	if len(summary.Lines) > 0 {
+		merchandise, _ := summary.Subtotal.Sub(summary.Discount)
+		shipping, err := shippingCost(dest, merchandise)
+		if err != nil {
+			return Summary{}, err
+		}
+		summary.Shipping = shipping
+	}
+
+	summary.GrandTotal, _ = money.Sum(currency, summary.Subtotal, summary.Tax, summary.Shipping)
+	summary.GrandTotal, _ = summary.GrandTotal.Sub(summary.Discount)
+	return summary, nil
+}
+
+// shippingCost is a flat rate in the settlement currency: SHIPPING_DOMESTIC
+// (free once the discounted merchandise reaches SHIPPING_FREE_OVER) inside
+// SHIPPING_HOME_COUNTRY and SHIPPING_INTERNATIONAL everywhere else
+func shippingCost(dest *Destination, merchandise money.Money) (money.Money, error) {
+	currency := merchandise.Currency
+	if dest != nil && countryCode(dest.Country) != homeCountry() {
+		return amountFromEnv("SHIPPING_INTERNATIONAL", "999", currency)
+	}
+	freeOver, err := amountFromEnv("SHIPPING_FREE_OVER", "499", currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if freeOver.Amount > 0 && merchandise.Amount >= freeOver.Amount {
+		return money.Zero(currency), nil
+	}
+	return amountFromEnv("SHIPPING_DOMESTIC", "49", currency)
+}
+
+func taxRate(dest *Destination) float64 {
+	rates := taxRates()
+	country := homeCountry()
+	state := ""
+	if dest != nil {
+		country = countryCode(dest.Country)
+		state = strings.ToUpper(strings.TrimSpace(dest.State))
+	}
+	if rate, ok := rates[country+"-"+state]; ok && state != "" {
+		return rate
+	}
+	return rates[country]
+}
+
+func taxRates() map[string]float64 {
+	configured := os.Getenv("TAX_RATES")
+	if configured == "" {
+		return defaultTaxRates
+	}
+	rates := map[string]float64{}
+	for _, entry := range strings.Split(configured, ",") {
+		region, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			continue
+		}
+		rates[strings.ToUpper(strings.TrimSpace(region))] = rate
+	}
+	return rates
+}
+
+func homeCountry() string {
+	if country := os.Getenv("SHIPPING_HOME_COUNTRY"); country != "" {
+		return countryCode(country)
+	}
+	return "IN"
+}
+
+func countryCode(country string) string {
+	code := strings.ToUpper(strings.TrimSpace(country))
+	if alias, ok := countryAliases[code]; ok {
+		return alias
+	}
+	return code
+}
+
+func amountFromEnv(key string, fallback string, currency money.Currency) (money.Money, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		value = fallback
+	}
+	amount, err := money.Parse(value, currency)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return amount, nil
+}
@@ -0,0 +1,164 @@
+package pricing
+
+import (
+	"errors"
+	"supernova/shared/money"
+	"testing"
+)
+
+// defaultEnv clears the pricing settings so the built-in defaults apply:
+// 18% tax in India, 49 domestic shipping free from 499, 999 abroad
+func defaultEnv(t *testing.T) {
+	for _, key := range []string{"TAX_RATES", "SHIPPING_HOME_COUNTRY", "SHIPPING_DOMESTIC", "SHIPPING_FREE_OVER", "SHIPPING_INTERNATIONAL"} {
+		t.Setenv(key, "")
+	}
+}
+
+func inr(minor int64) money.Money { return money.New(minor, money.INR) }
+
+func rates(table map[money.Currency]float64) RateFunc {
+	return func(from money.Currency) (float64, error) {
+		if from == money.INR {
+			return 1, nil
+		}
+		if rate, ok := table[from]; ok {
+			return rate, nil
+		}
+		return 0, errors.New("no rate for " + string(from))
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name                                          string
+		lines                                         []Line
+		dest                                          *Destination
+		subtotal, discount, tax, shipping, grandTotal int64
+	}{
+		{
+			name:     "domestic below free shipping",
+			lines:    []Line{{ProductID: "a", UnitPrice: inr(19999), Quantity: 2}},
+			subtotal: 39998, tax: 7200, shipping: 4900, grandTotal: 52098, // 18% of 399.98 is 71.9964
+		},
+		{
+			name:     "free shipping from the threshold",
+			lines:    []Line{{ProductID: "a", UnitPrice: inr(49900), Quantity: 1}},
+			dest:     &Destination{Country: "india"},
+			subtotal: 49900, tax: 8982, grandTotal: 58882,
+		},
+		{
+			name: "threshold counts the discounted merchandise",
+			lines: []Line{
+				{ProductID: "a", UnitPrice: inr(30000), Quantity: 1},
+				{ProductID: "b", UnitPrice: inr(25000), Quantity: 1, Discount: inr(10000)},
+			},
+			subtotal: 55000, discount: 10000, tax: 8100, shipping: 4900, grandTotal: 58000,
+		},
+		{
+			name:     "discount capped at the line subtotal",
+			lines:    []Line{{ProductID: "a", UnitPrice: inr(10000), Quantity: 1, Discount: inr(15000)}},
+			subtotal: 10000, discount: 10000, shipping: 4900, grandTotal: 4900,
+		},
+		{
+			name:     "converted before multiplying",
+			lines:    []Line{{ProductID: "a", UnitPrice: money.New(1999, money.USD), Quantity: 3, Discount: money.New(100, money.USD)}},
+			subtotal: 498471, discount: 8312, tax: 88229, grandTotal: 578388, // 19.99 USD is 1661.57 INR
+		},
+		{
+			name:     "international without a tax rate",
+			lines:    []Line{{ProductID: "a", UnitPrice: inr(100000), Quantity: 1}},
+			dest:     &Destination{Country: "USA"},
+			subtotal: 100000, shipping: 99900, grandTotal: 199900,
+		},
+		{
+			name: "empty cart pays no shipping",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultEnv(t)
+			summary, err := Calculate(tt.lines, money.INR, rates(map[money.Currency]float64{money.USD: 83.12}), tt.dest)
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+			got := []money.Money{summary.Subtotal, summary.Discount, summary.Tax, summary.Shipping, summary.GrandTotal}
+			want := []int64{tt.subtotal, tt.discount, tt.tax, tt.shipping, tt.grandTotal}
+			names := []string{"subtotal", "discount", "tax", "shipping", "grand total"}
+			for i := range got {
+				if got[i].Amount != want[i] || got[i].Currency != money.INR {
+					t.Errorf("%s = %v, want %d INR", names[i], got[i], want[i])
+				}
+			}
+			if len(summary.Lines) != len(tt.lines) {
+				t.Fatalf("got %d lines, want %d", len(summary.Lines), len(tt.lines))
+			}
+			var lineTotals int64
+			for _, line := range summary.Lines {
+				lineTotals += line.Total.Amount
+			}
+			if lineTotals+summary.Shipping.Amount != summary.GrandTotal.Amount {
+				t.Errorf("line totals %d plus shipping %d != grand total %d", lineTotals, summary.Shipping.Amount, summary.GrandTotal.Amount)
+			}
+		})
+	}
+}
+
+func TestCalculateMissingRate(t *testing.T) {
+	defaultEnv(t)
+	lines := []Line{{ProductID: "a", UnitPrice: money.New(1000, money.EUR), Quantity: 1}}
+	if _, err := Calculate(lines, money.INR, rates(nil), nil); err == nil {
+		t.Error("Calculate with no EUR rate succeeded, want an error")
+	}
+}
+
+func TestTaxRate(t *testing.T) {
+	t.Setenv("TAX_RATES", "IN=18, US=5,US-CA=7.25,bad,XX=abc")
+	t.Setenv("SHIPPING_HOME_COUNTRY", "")
+
+	tests := []struct {
+		dest *Destination
+		want float64
+	}{
+		{nil, 18},
+		{&Destination{Country: "India"}, 18},
+		{&Destination{Country: "United States", State: "ca"}, 7.25},
+		{&Destination{Country: "US", State: "NY"}, 5},
+		{&Destination{Country: "XX"}, 0},
+		{&Destination{Country: "FR"}, 0},
+	}
+	for _, tt := range tests {
+		if got := taxRate(tt.dest); got != tt.want {
+			t.Errorf("taxRate(%+v) = %v, want %v", tt.dest, got, tt.want)
+		}
+	}
+}
+
+func TestShippingSettings(t *testing.T) {
+	defaultEnv(t)
+	t.Setenv("SHIPPING_HOME_COUNTRY", "US")
+	t.Setenv("SHIPPING_DOMESTIC", "5.99")
+	t.Setenv("SHIPPING_FREE_OVER", "0")
+
+	shipping, err := shippingCost(&Destination{Country: "usa"}, money.New(100000, money.USD))
+	if err != nil || shipping.Amount != 599 {
+		t.Errorf("domestic shipping with free shipping off = %v, %v; want 5.99", shipping, err)
+	}
+
+	t.Setenv("SHIPPING_INTERNATIONAL", "twelve")
+	if _, err := shippingCost(&Destination{Country: "IN"}, money.New(100, money.USD)); err == nil {
+		t.Error("invalid SHIPPING_INTERNATIONAL accepted")
+	}
+}
+
+func TestExchangeRate(t *testing.T) {
+	table := map[string]float64{"USD": 1, "INR": 83.12, "EUR": 0.92}
+	if rate, _ := ExchangeRate(table, money.USD, money.INR); rate != 83.12 {
+		t.Errorf("USD->INR = %v, want 83.12", rate)
+	}
+	if rate, _ := ExchangeRate(table, money.JPY, money.JPY); rate != 1 {
+		t.Errorf("same currency rate = %v, want 1", rate)
+	}
+	if _, err := ExchangeRate(table, money.GBP, money.INR); err == nil {
+		t.Error("missing GBP rate accepted")
+	}
+}
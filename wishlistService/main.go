@@ -0,0 +1,15 @@
+package main
+
+import (
+	"supernova/wishlistService/wishlist"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	router := gin.Default()
+
+	wishlist.SetupWishlistApp(router)
+
+	router.Run(":8089")
+}
@@ -0,0 +1,194 @@
+package broker
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	conn         *amqp.Connection
+	channel      *amqp.Channel
+	notifyClose  chan *amqp.Error
+	mutex        sync.Mutex
+	amqpURL      string
+	retryBackoff = 5 * time.Second
+)
+
+// Connect initializes RabbitMQ connection and channel (idempotent)
+func Connect() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if amqpURL == "" {
+		amqpURL = os.Getenv("AMQP_SERVER_URL")
+		if amqpURL == "" {
+			log.Fatal("❌ wishlistService AMQP_SERVER_URL not set")
+		}
+	}
+
+	for {
+		var err error
+		log.Println("🔁 wishlistService Connecting to RabbitMQ...")
+		conn, err = amqp.Dial(amqpURL)
+		if err != nil {
+			log.Println("⚠️ wishlistService Failed to connect:", err)
+			time.Sleep(retryBackoff)
+			continue
+		}
+
+		channel, err = conn.Channel()
+		if err != nil {
+			log.Println("⚠️ wishlistService Failed to open channel:", err)
+			_ = conn.Close()
+			time.Sleep(retryBackoff)
+			continue
+		}
+
+		notifyClose = make(chan *amqp.Error)
+		channel.NotifyClose(notifyClose)
+
+		// Launch reconnect handler in background
+		go handleReconnect(notifyClose)
+
+		log.Println("✅ wishlistService Connected to RabbitMQ")
+		return
+	}
+}
+
+func handleReconnect(nc chan *amqp.Error) {
+	err := <-nc
+	if err != nil {
+		log.Printf("🚨 wishlistService RabbitMQ closed: %v. Reconnecting...", err)
+	} else {
+		log.Println("ℹ️ wishlistService RabbitMQ NotifyClose returned nil. Reconnecting...")
+	}
+
+	mutex.Lock()
+	if channel != nil {
+		_ = channel.Close()
+	}
+	if conn != nil {
+		_ = conn.Close()
+	}
+	channel, conn = nil, nil
+	mutex.Unlock()
+
+	// reconnect in background
+	for {
+		Connect()
+		mutex.Lock()
+		ok := conn != nil && channel != nil
+		mutex.Unlock()
+		if ok {
+			log.Println("✅ wishlistService Reconnected to RabbitMQ (background)")
+			return
+		}
+		time.Sleep(retryBackoff)
+	}
+}
+
+// PublishJSON sends a persistent JSON message to a queue
+func PublishJSON(queueName string, body []byte) error {
+	if conn == nil || channel == nil {
+		Connect()
+	}
+
+	mutex.Lock()
+	ch := channel
+	mutex.Unlock()
+	if ch == nil {
+		return amqp.ErrClosed
+	}
+	_, err := ch.QueueDeclare(
+		queueName, // queue name
+		true,      // durable
+		false,     // auto-delete
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	err = ch.Publish(
+		"", queueName, false, false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		log.Println("❌ wishlistService Publish failed, reconnecting...")
+		Connect()
+		mutex.Lock()
+		ch = channel
+		mutex.Unlock()
+		if ch == nil {
+			return amqp.ErrClosed
+		}
+		return ch.Publish(
+			"", queueName, false, false,
+			amqp.Publishing{
+				ContentType:  "application/json",
+				Body:         body,
+				DeliveryMode: amqp.Persistent,
+				Timestamp:    time.Now(),
+			},
+		)
+	}
+
+	log.Printf("📤 wishlistService Sent message to %s", queueName)
+	return nil
+}
+
+// MessageHandler processes the body of a message taken from a queue.
+// The broker package can't import controllers, so handlers are passed in.
+type MessageHandler func(body []byte)
+
+// ConsumeQueues sets up a consumer for every queue in handlers
+func ConsumeQueues(handlers map[string]MessageHandler) {
+	if conn == nil || channel == nil {
+		Connect()
+	}
+
+	for q, handler := range handlers {
+		_, err := channel.QueueDeclare(q, true, false, false, false, nil)
+		if err != nil {
+			log.Fatalf("❌ wishlistService Failed to declare queue %s: %v", q, err)
+		}
+
+		msgs, err := channel.Consume(q, "", false, false, false, false, nil)
+		if err != nil {
+			log.Fatalf("❌ wishlistService Failed to consume queue %s: %v", q, err)
+		}
+
+		go func(queue string, handler MessageHandler, msgs <-chan amqp.Delivery) {
+			for msg := range msgs {
+				handler(msg.Body)
+				msg.Ack(false)
+			}
+		}(q, handler, msgs)
+		log.Println("✅ wishlistService Consumer started for queue:", q)
+	}
+}
+
+// GetChannel returns current channel
+func GetChannel() *amqp.Channel {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return channel
+}
+
+// GetConnection returns current connection
+func GetConnection() *amqp.Connection {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return conn
+}
@@ -0,0 +1,9 @@
+package db
+
+import "go.mongodb.org/mongo-driver/mongo"
+
+var wishlistCollection *mongo.Collection
+
+func GetWishlistCollection() *mongo.Collection {
+	return wishlistCollection
+}
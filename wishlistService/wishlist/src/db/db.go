@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func InitDB() {
+	mongoURI := os.Getenv("MONGO_URI")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatal("Error connecting to MongoDB: ", err)
+	}
+
+	log.Printf("✅ Wishlist Service Connected to MongoDB")
+
+	wishlistCollection = client.Database("supernovaWishlistDB").Collection("wishlists")
+
+	createIndexes(ctx)
+}
+
+// createIndexes keeps list names unique per user, share tokens unique and
+// product lookups from events cheap
+func createIndexes(ctx context.Context) {
+	_, err := wishlistCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "shareToken", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"shareToken": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "items.productId", Value: 1}},
+		},
+	})
+	if err != nil {
+		log.Printf("⚠️ Wishlist Service failed to create indexes: %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+package dto
+
+// CartItem is the body cartService expects when adding an item;
+// the cart prices it from the product service
+type CartItem struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"supernova/shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WishlistItemData is sent to emailService when a saved product gets cheaper
+// or comes back in stock
+type WishlistItemData struct {
+	ReceiverMail string             `json:"receiverMail"`
+	WishlistID   primitive.ObjectID `json:"wishlistId"`
+	WishlistName string             `json:"wishlistName"`
+	ProductID    primitive.ObjectID `json:"productId"`
+	ProductName  string             `json:"productName"`
+	OldPrice     money.Money        `json:"oldPrice"`
+	Price        money.Money        `json:"price"`
+}
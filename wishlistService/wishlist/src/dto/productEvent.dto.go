@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"supernova/shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductSnapshot is the part of a product event the wishlist compares against
+type ProductSnapshot struct {
+	Title string      `json:"title"`
+	Price money.Money `json:"price"`
+	Stock int         `json:"stock"`
+}
+
+// ProductEvent is the payload of the ProductEventsWishlist queue
+type ProductEvent struct {
+	Type      string             `json:"type"`
+	ProductID primitive.ObjectID `json:"_id"`
+	Version   int64              `json:"version"`
+	Product   *ProductSnapshot   `json:"product,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+// WishlistDTO creates or renames a list
+type WishlistDTO struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// ItemDTO adds a product to a list
+type ItemDTO struct {
+	ProductID string `json:"productId" binding:"required"`
+}
+
+// MoveToCartDTO moves a saved product into the cart; Quantity defaults to 1
+type MoveToCartDTO struct {
+	Quantity int `json:"quantity" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,136 @@
+package wishlistcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"supernova/wishlistService/wishlist/src/broker"
+	"supernova/wishlistService/wishlist/src/db"
+	"supernova/wishlistService/wishlist/src/dto"
+	wishlistmodel "supernova/wishlistService/wishlist/src/wishlistModel"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Notification queues read by emailService
+const (
+	WishlistItemPriceDropped = "WishlistItemPriceDropped"
+	WishlistItemBackInStock  = "WishlistItemBackInStock"
+)
+
+// HandleProductEvent copies product changes onto saved items and tells the
+// owner when one got cheaper or came back in stock. Each list is updated with
+// a version guard, so a redelivered or stale event never notifies twice.
+func HandleProductEvent(body []byte) {
+	var event dto.ProductEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("❌ wishlistService invalid product event: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wishlists := db.GetWishlistCollection()
+	switch event.Type {
+	case "ProductUpdated", "ProductStockChanged":
+		if event.Product == nil {
+			return
+		}
+		staleLine := bson.M{
+			"productId": event.ProductID,
+			"$or": bson.A{
+				bson.M{"productVersion": bson.M{"$exists": false}},
+				bson.M{"productVersion": bson.M{"$lt": event.Version}},
+			},
+		}
+		cursor, err := wishlists.Find(ctx, bson.M{"items": bson.M{"$elemMatch": staleLine}})
+		if err != nil {
+			log.Printf("❌ wishlistService failed to load wishlists for product %s: %v", event.ProductID.Hex(), err)
+			return
+		}
+		var affected []wishlistmodel.Wishlist
+		if err := cursor.All(ctx, &affected); err != nil {
+			log.Printf("❌ wishlistService failed to decode wishlists for product %s: %v", event.ProductID.Hex(), err)
+			return
+		}
+
+		inStock := event.Product.Stock > 0
+		for _, wishlist := range affected {
+			var saved *wishlistmodel.Item
+			for i := range wishlist.Items {
+				if wishlist.Items[i].ProductID == event.ProductID {
+					saved = &wishlist.Items[i]
+					break
+				}
+			}
+			if saved == nil {
+				continue
+			}
+
+			res, err := wishlists.UpdateOne(ctx,
+				bson.M{"_id": wishlist.ID, "items": bson.M{"$elemMatch": staleLine}},
+				bson.M{"$set": bson.M{
+					"items.$[line].title":          event.Product.Title,
+					"items.$[line].price":          event.Product.Price,
+					"items.$[line].inStock":        inStock,
+					"items.$[line].productVersion": event.Version,
+				}},
+				options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+					bson.M{"line.productId": event.ProductID},
+				}}),
+			)
+			if err != nil {
+				log.Printf("❌ wishlistService failed to update wishlist %s: %v", wishlist.ID.Hex(), err)
+				continue
+			}
+			// another delivery got there first
+			if res.ModifiedCount == 0 {
+				continue
+			}
+
+			data := dto.WishlistItemData{
+				ReceiverMail: wishlist.Email,
+				WishlistID:   wishlist.ID,
+				WishlistName: wishlist.Name,
+				ProductID:    event.ProductID,
+				ProductName:  event.Product.Title,
+				OldPrice:     saved.Price,
+				Price:        event.Product.Price,
+			}
+			if saved.Price.Currency == event.Product.Price.Currency && event.Product.Price.Amount < saved.Price.Amount {
+				notify(WishlistItemPriceDropped, data)
+			}
+			if !saved.InStock && inStock {
+				notify(WishlistItemBackInStock, data)
+			}
+		}
+	case "ProductDeleted":
+		_, err := wishlists.UpdateMany(ctx,
+			bson.M{"items.productId": event.ProductID},
+			bson.M{
+				"$pull": bson.M{"items": bson.M{"productId": event.ProductID}},
+				"$set":  bson.M{"updatedAt": time.Now()},
+			},
+		)
+		if err != nil {
+			log.Printf("❌ wishlistService failed to remove product %s: %v", event.ProductID.Hex(), err)
+		}
+	}
+}
+
+func notify(queue string, data dto.WishlistItemData) {
+	if data.ReceiverMail == "" {
+		return
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("❌ wishlistService failed to encode %s: %v", queue, err)
+		return
+	}
+	if err := broker.PublishJSON(queue, body); err != nil {
+		log.Printf("❌ wishlistService failed to publish %s: %v", queue, err)
+	}
+}
@@ -0,0 +1,89 @@
+package wishlistcontroller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"supernova/wishlistService/wishlist/src/dto"
+	"time"
+)
+
+var serviceClient = &http.Client{Timeout: 10 * time.Second}
+
+var errProductNotFound = errors.New("product not found")
+
+// cartError carries the cart service's refusal back to the caller
+type cartError struct {
+	Status  int
+	Message string
+}
+
+func (e *cartError) Error() string {
+	return e.Message
+}
+
+// quoteProduct asks the product service whether a product exists and what it costs now
+func quoteProduct(productID string) (dto.QuoteLine, error) {
+	var quote dto.QuoteResponse
+
+	body, err := json.Marshal(dto.QuoteRequest{Items: []dto.QuoteItem{{ProductID: productID, Quantity: 1}}})
+	if err != nil {
+		return dto.QuoteLine{}, fmt.Errorf("failed to encode quote request")
+	}
+
+	resp, err := serviceClient.Post(os.Getenv("PRODUCT_SERVICE_URL")+"/api/product/quote", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return dto.QuoteLine{}, fmt.Errorf("failed to connect to product service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		return dto.QuoteLine{}, errProductNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return dto.QuoteLine{}, fmt.Errorf("product service failed with status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return dto.QuoteLine{}, fmt.Errorf("failed to decode quote")
+	}
+	if len(quote.Items) == 0 {
+		return dto.QuoteLine{}, errProductNotFound
+	}
+	return quote.Items[0], nil
+}
+
+// addToCart puts a product in the caller's cart on their behalf
+func addToCart(token string, item dto.CartItem) error {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode cart item")
+	}
+
+	req, err := http.NewRequest("POST", os.Getenv("CART_SERVICE_URL")+"/api/cart/item", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create cart request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := serviceClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to cart service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		if body.Error == "" {
+			body.Error = fmt.Sprintf("cart service failed with status: %d", resp.StatusCode)
+		}
+		return &cartError{Status: resp.StatusCode, Message: body.Error}
+	}
+	return nil
+}
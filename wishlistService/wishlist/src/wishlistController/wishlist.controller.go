@@ -0,0 +1,436 @@
+package wishlistcontroller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"supernova/wishlistService/wishlist/src/db"
+	"supernova/wishlistService/wishlist/src/dto"
+	wishlistmodel "supernova/wishlistService/wishlist/src/wishlistModel"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ownerFilter matches the list in the :id param only if it belongs to the caller
+func ownerFilter(c *gin.Context) (bson.M, bool) {
+	userID, err := primitive.ObjectIDFromHex(c.GetString("UserID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return nil, false
+	}
+	listID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wishlist ID format"})
+		return nil, false
+	}
+	return bson.M{"_id": listID, "userId": userID}, true
+}
+
+// findOwnedList loads the caller's list, answering 404 if there is none
+func findOwnedList(ctx context.Context, c *gin.Context, filter bson.M) (wishlistmodel.Wishlist, bool) {
+	var wishlist wishlistmodel.Wishlist
+	err := db.GetWishlistCollection().FindOne(ctx, filter).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+			return wishlist, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return wishlist, false
+	}
+	return wishlist, true
+}
+
+func CreateWishlist(c *gin.Context) {
+	var wishlistDTO dto.WishlistDTO
+	if err := c.ShouldBindJSON(&wishlistDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(c.GetString("UserID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wishlist := wishlistmodel.Wishlist{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Email:     c.GetString("Email"),
+		Name:      wishlistDTO.Name,
+		Items:     []wishlistmodel.Item{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if _, err := db.GetWishlistCollection().InsertOne(ctx, wishlist); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A wishlist with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Wishlist created", "wishlist": wishlist})
+}
+
+func GetWishlists(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.GetString("UserID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := db.GetWishlistCollection().Find(ctx, bson.M{"userId": userID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	wishlists := []wishlistmodel.Wishlist{}
+	if err := cursor.All(ctx, &wishlists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"wishlists": wishlists})
+}
+
+func GetWishlist(c *gin.Context) {
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wishlist, ok := findOwnedList(ctx, c, filter)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"wishlist": wishlist})
+}
+
+func RenameWishlist(c *gin.Context) {
+	var wishlistDTO dto.WishlistDTO
+	if err := c.ShouldBindJSON(&wishlistDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wishlist wishlistmodel.Wishlist
+	err := db.GetWishlistCollection().FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"name": wishlistDTO.Name, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A wishlist with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wishlist renamed", "wishlist": wishlist})
+}
+
+func DeleteWishlist(c *gin.Context) {
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := db.GetWishlistCollection().DeleteOne(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if res.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wishlist deleted"})
+}
+
+func AddItemToWishlist(c *gin.Context) {
+	var itemDTO dto.ItemDTO
+	if err := c.ShouldBindJSON(&itemDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	productID, err := primitive.ObjectIDFromHex(itemDTO.ProductID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wishlist, ok := findOwnedList(ctx, c, filter)
+	if !ok {
+		return
+	}
+	for _, item := range wishlist.Items {
+		if item.ProductID == productID {
+			c.JSON(http.StatusOK, gin.H{"message": "Product already in wishlist", "wishlist": wishlist})
+			return
+		}
+	}
+
+	line, err := quoteProduct(productID.Hex())
+	if err != nil {
+		if errors.Is(err, errProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	item := wishlistmodel.Item{
+		ProductID: productID,
+		Title:     line.Title,
+		Price:     line.OriginalPrice,
+		InStock:   line.Stock > 0,
+		AddedAt:   time.Now(),
+	}
+	// the $ne guard keeps a concurrent add of the same product from duplicating it
+	filter["items.productId"] = bson.M{"$ne": productID}
+	err = db.GetWishlistCollection().FindOneAndUpdate(ctx, filter,
+		bson.M{
+			"$push": bson.M{"items": item},
+			"$set":  bson.M{"updatedAt": time.Now(), "email": c.GetString("Email")},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&wishlist)
+	if err != nil && err != mongo.ErrNoDocuments {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item added to wishlist", "wishlist": wishlist})
+}
+
+func RemoveItemFromWishlist(c *gin.Context) {
+	productID, err := primitive.ObjectIDFromHex(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wishlist wishlistmodel.Wishlist
+	err = db.GetWishlistCollection().FindOneAndUpdate(ctx, filter,
+		bson.M{
+			"$pull": bson.M{"items": bson.M{"productId": productID}},
+			"$set":  bson.M{"updatedAt": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item removed from wishlist", "wishlist": wishlist})
+}
+
+// MoveItemToCart adds a saved product to the cart, which prices it, and then
+// drops it from the list. The item stays on the list if the cart refuses it.
+func MoveItemToCart(c *gin.Context) {
+	var moveDTO dto.MoveToCartDTO
+	if err := c.ShouldBindJSON(&moveDTO); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if moveDTO.Quantity == 0 {
+		moveDTO.Quantity = 1
+	}
+	productID, err := primitive.ObjectIDFromHex(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wishlist, ok := findOwnedList(ctx, c, filter)
+	if !ok {
+		return
+	}
+	found := false
+	for _, item := range wishlist.Items {
+		if item.ProductID == productID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not in wishlist"})
+		return
+	}
+
+	err = addToCart(c.GetString("Token"), dto.CartItem{
+		ProductID: productID.Hex(),
+		Quantity:  moveDTO.Quantity,
+	})
+	if err != nil {
+		// the cart's own 404 or 409 (gone, not enough stock) is passed through
+		var refused *cartError
+		if errors.As(err, &refused) && refused.Status < http.StatusInternalServerError {
+			c.JSON(refused.Status, gin.H{"error": refused.Message})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = db.GetWishlistCollection().FindOneAndUpdate(ctx, filter,
+		bson.M{
+			"$pull": bson.M{"items": bson.M{"productId": productID}},
+			"$set":  bson.M{"updatedAt": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&wishlist)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Item added to cart but failed to update wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item moved to cart", "wishlist": wishlist})
+}
+
+// ShareWishlist gives the list a public link; calling it again returns the same link
+func ShareWishlist(c *gin.Context) {
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wishlist, ok := findOwnedList(ctx, c, filter)
+	if !ok {
+		return
+	}
+
+	if wishlist.ShareToken == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+			return
+		}
+		wishlist.ShareToken = hex.EncodeToString(buf)
+		_, err := db.GetWishlistCollection().UpdateOne(ctx, filter,
+			bson.M{"$set": bson.M{"shareToken": wishlist.ShareToken, "updatedAt": time.Now()}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shareToken": wishlist.ShareToken,
+		"path":       "/api/wishlist/shared/" + wishlist.ShareToken,
+	})
+}
+
+func UnshareWishlist(c *gin.Context) {
+	filter, ok := ownerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := db.GetWishlistCollection().UpdateOne(ctx, filter,
+		bson.M{"$unset": bson.M{"shareToken": ""}, "$set": bson.M{"updatedAt": time.Now()}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if res.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link removed"})
+}
+
+// GetSharedWishlist is public: anyone holding the token sees the list name and items
+func GetSharedWishlist(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wishlist wishlistmodel.Wishlist
+	err := db.GetWishlistCollection().FindOne(ctx, bson.M{"shareToken": c.Param("token")}).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":      wishlist.Name,
+		"items":     wishlist.Items,
+		"updatedAt": wishlist.UpdatedAt,
+	})
+}
@@ -0,0 +1,33 @@
+package wishlistmodel
+
+import (
+	"supernova/shared/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Item is a saved product. Title, Price and InStock are the product's state
+// when it was last seen, so product events can tell a price drop or a
+// restock apart from any other change.
+type Item struct {
+	ProductID      primitive.ObjectID `bson:"productId" json:"productId"`
+	Title          string             `bson:"title" json:"title"`
+	Price          money.Money        `bson:"price" json:"price"`
+	InStock        bool               `bson:"inStock" json:"inStock"`
+	ProductVersion int64              `bson:"productVersion,omitempty" json:"productVersion,omitempty"`
+	AddedAt        time.Time          `bson:"addedAt" json:"addedAt"`
+}
+
+// Wishlist is one named list; a user can have several.
+// Email is kept so owners can be notified without asking authService.
+type Wishlist struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	Email      string             `bson:"email" json:"-"`
+	Name       string             `bson:"name" json:"name"`
+	Items      []Item             `bson:"items" json:"items"`
+	ShareToken string             `bson:"shareToken,omitempty" json:"shareToken,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,29 @@
+package wishlistroutes
+
+import (
+	wishlistcontroller "supernova/wishlistService/wishlist/src/wishlistController"
+	wishlistmiddleware "supernova/wishlistService/wishlist/src/wishlistMiddleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupWishlistRoutes(router *gin.Engine) {
+
+	r := router.Group("/api/wishlist")
+
+	// share links are public, so register before the auth middleware
+	r.GET("/shared/:token", wishlistcontroller.GetSharedWishlist)
+
+	securedRoutes := r.Use(wishlistmiddleware.CreateAuthMiddleware())
+
+	securedRoutes.POST("/create", wishlistcontroller.CreateWishlist)
+	securedRoutes.GET("/get", wishlistcontroller.GetWishlists)
+	securedRoutes.GET("/:id", wishlistcontroller.GetWishlist)
+	securedRoutes.PATCH("/:id", wishlistcontroller.RenameWishlist)
+	securedRoutes.DELETE("/:id", wishlistcontroller.DeleteWishlist)
+	securedRoutes.POST("/:id/item", wishlistcontroller.AddItemToWishlist)
+	securedRoutes.DELETE("/:id/item/:productId", wishlistcontroller.RemoveItemFromWishlist)
+	securedRoutes.POST("/:id/item/:productId/move", wishlistcontroller.MoveItemToCart)
+	securedRoutes.POST("/:id/share", wishlistcontroller.ShareWishlist)
+	securedRoutes.DELETE("/:id/share", wishlistcontroller.UnshareWishlist)
+}
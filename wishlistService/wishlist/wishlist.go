@@ -0,0 +1,29 @@
+package wishlist
+
+import (
+	"log"
+	"supernova/wishlistService/wishlist/src/broker"
+	"supernova/wishlistService/wishlist/src/db"
+	wishlistcontroller "supernova/wishlistService/wishlist/src/wishlistController"
+	wishlistroutes "supernova/wishlistService/wishlist/src/wishlistRoutes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+)
+
+func SetupWishlistApp(router *gin.Engine) {
+
+	err := godotenv.Load()
+	if err != nil {
+		log.Print("Error loading .env file")
+	}
+
+	db.InitDB()
+	broker.Connect()
+	broker.ConsumeQueues(map[string]broker.MessageHandler{
+		"ProductEventsWishlist": wishlistcontroller.HandleProductEvent,
+	})
+
+	wishlistroutes.SetupWishlistRoutes(router)
+
+}